@@ -6,6 +6,7 @@ import (
 	"os"
 	"text/tabwriter"
 	"tokit/internal/chain"
+	"tokit/internal/units"
 	"tokit/internal/utils"
 	"tokit/internal/wallet"
 
@@ -17,7 +18,7 @@ var balanceTokenAddress string
 var balanceCmd = &cobra.Command{
 	Use:   "balance [chain] [address]",
 	Short: "Check account balance",
-	Long:  `Check the balance of an account on a specific blockchain. If address is omitted, checks the first local wallet account.`,
+	Long:  `Check the balance of an account on a specific blockchain. If address is omitted, checks the --from account or the first local wallet account.`,
 	Args:  cobra.RangeArgs(0, 2),
 	Run: func(cmd *cobra.Command, args []string) {
 		chainName := AppConfig.Default
@@ -27,18 +28,13 @@ var balanceCmd = &cobra.Command{
 
 		var address string
 		if len(args) > 1 {
-			address = args[1]
+			address = resolveAddress(args[1])
 		} else {
-			// Get first account from local wallet
 			svc, err := wallet.NewService()
 			if err != nil {
 				utils.Log.Fatalf("Failed to init wallet service: %v", err)
 			}
-			accounts := svc.ListAccounts()
-			if len(accounts) == 0 {
-				utils.Log.Fatal("No local accounts found. Please provide an address or create a wallet.")
-			}
-			address = accounts[0].Address.Hex()
+			address = senderAccount(svc).Address.Hex()
 		}
 
 		client, err := chain.NewClient(chainName, AppConfig)
@@ -48,37 +44,44 @@ var balanceCmd = &cobra.Command{
 		defer client.Close()
 
 		var balance *big.Int
-		var symbol string
+		symbol := client.Config.Symbol
+		decimals := units.EtherDecimals
 
 		if balanceTokenAddress != "" {
 			// Check Token Balance
+			info, err := client.GetTokenInfo(balanceTokenAddress)
+			if err != nil {
+				utils.Log.Fatalf("Failed to get token info: %v", err)
+			}
 			balance, err = client.GetTokenBalance(balanceTokenAddress, address)
 			if err != nil {
 				utils.Log.Fatalf("Failed to get token balance: %v", err)
 			}
-			symbol = "TOKEN" // TODO: Fetch symbol from contract
+			symbol, decimals = tokenSymbol(info), int(info.Decimals)
 		} else {
 			// Check ETH Balance
 			balance, err = client.GetBalance(address)
 			if err != nil {
 				utils.Log.Fatalf("Failed to get balance: %v", err)
 			}
-			symbol = client.Config.Symbol
 		}
 
-		// Convert Wei to Ether (assuming 18 decimals)
-		fBalance := new(big.Float)
-		fBalance.SetString(balance.String())
-		ethValue := new(big.Float).Quo(fBalance, big.NewFloat(1e18))
-
 		w := new(tabwriter.Writer)
 		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
 		fmt.Fprintln(w, "Chain\tAddress\tBalance\tSymbol")
-		fmt.Fprintf(w, "%s\t%s\t%.6f\t%s\n", chainName, address, ethValue, symbol)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", chainName, address, units.FormatUnits(balance, decimals), symbol)
 		w.Flush()
 	},
 }
 
+// tokenSymbol returns the token's symbol, or a placeholder for tokens that don't define one
+func tokenSymbol(info *chain.TokenInfo) string {
+	if info.Symbol == "" {
+		return "TOKEN"
+	}
+	return info.Symbol
+}
+
 func init() {
 	rootCmd.AddCommand(balanceCmd)
 	balanceCmd.Flags().StringVarP(&balanceTokenAddress, "token", "t", "", "ERC20 token address")
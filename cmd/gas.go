@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"text/tabwriter"
+	"tokit/internal/chain"
+	"tokit/internal/units"
+	"tokit/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// transferGas is the gas used by a plain native transfer, used to show example costs
+const transferGas = 21000
+
+var gasCmd = &cobra.Command{
+	Use:   "gas [chain]",
+	Short: "Show current fees on a network",
+	Long: `Show the next block's base fee, the base fee range over recent blocks and the fees of the
+slow, normal and fast presets, taken from eth_feeHistory reward percentiles.
+
+On networks without EIP-1559 the presets are gas prices derived from eth_gasPrice.`,
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		chainName := AppConfig.Default
+		if len(args) > 0 {
+			chainName = args[0]
+		}
+
+		client := newClient(chainName)
+		defer client.Close()
+
+		defaultSpeed := client.Config.Speed
+		if defaultSpeed == "" {
+			defaultSpeed = chain.SpeedNormal
+		}
+
+		txType, err := client.TxType()
+		if err != nil {
+			utils.Log.Fatalf("Failed to get transaction type: %v", err)
+		}
+		if txType != chain.TxTypeDynamic {
+			printGasPrices(client, chainName, txType, defaultSpeed)
+			return
+		}
+
+		landscape, err := client.GetFeeLandscape()
+		if err != nil {
+			utils.Log.Fatalf("Failed to get fees: %v", err)
+		}
+
+		fmt.Printf("Network:         %s (chain %s)\n", chainName, client.ChainID)
+		fmt.Printf("Next Base Fee:   %s gwei\n", gwei(landscape.NextBaseFee))
+		fmt.Printf("Recent Range:    %s - %s gwei (since block %d)\n", gwei(landscape.MinBaseFee), gwei(landscape.MaxBaseFee), landscape.OldestBlock)
+		fmt.Printf("Block Usage:     %.0f%%\n\n", landscape.GasUsedRatio*100)
+
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "Speed\tPriority Fee\tMax Fee\tTransfer Cost")
+		for _, speed := range chain.Speeds {
+			fees := landscape.Presets[speed]
+			// A transaction pays the base fee plus its tip, up to its max fee
+			price := new(big.Int).Add(landscape.NextBaseFee, fees.PriorityFee)
+			if price.Cmp(fees.MaxFee) > 0 {
+				price = fees.MaxFee
+			}
+			cost := new(big.Int).Mul(price, big.NewInt(transferGas))
+
+			name := speed
+			if speed == defaultSpeed {
+				name += " (default)"
+			}
+			fmt.Fprintf(w, "%s\t%s gwei\t%s gwei\t%s %s\n", name, gwei(fees.PriorityFee), gwei(fees.MaxFee),
+				units.FormatUnits(cost, units.EtherDecimals), client.Config.Symbol)
+		}
+		w.Flush()
+	},
+}
+
+// printGasPrices prints the gas price presets of a network without EIP-1559
+func printGasPrices(client *chain.Client, chainName, txType, defaultSpeed string) {
+	prices, err := client.GetGasPrices()
+	if err != nil {
+		utils.Log.Fatalf("Failed to get fees: %v", err)
+	}
+
+	fmt.Printf("Network:         %s (chain %s)\n", chainName, client.ChainID)
+	fmt.Printf("Tx Type:         %s (no base fee)\n\n", txType)
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+	fmt.Fprintln(w, "Speed\tGas Price\tTransfer Cost")
+	for _, speed := range chain.Speeds {
+		cost := new(big.Int).Mul(prices[speed], big.NewInt(transferGas))
+
+		name := speed
+		if speed == defaultSpeed {
+			name += " (default)"
+		}
+		fmt.Fprintf(w, "%s\t%s gwei\t%s %s\n", name, gwei(prices[speed]),
+			units.FormatUnits(cost, units.EtherDecimals), client.Config.Symbol)
+	}
+	w.Flush()
+}
+
+// gwei formats a wei amount in gwei
+func gwei(wei *big.Int) string {
+	return units.FormatUnits(wei, units.GweiDecimals)
+}
+
+func init() {
+	rootCmd.AddCommand(gasCmd)
+}
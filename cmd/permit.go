@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"tokit/internal/units"
+	"tokit/internal/utils"
+	"tokit/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	permitDeadline string
+	permitOutput   string
+)
+
+// permitPayload is everything a relayer needs to submit permit(owner, spender, value, deadline, v, r, s)
+type permitPayload struct {
+	Token     string             `json:"token"`
+	ChainID   string             `json:"chainId"`
+	Owner     string             `json:"owner"`
+	Spender   string             `json:"spender"`
+	Value     string             `json:"value"`
+	Nonce     string             `json:"nonce"`
+	Deadline  string             `json:"deadline"`
+	V         uint8              `json:"v"`
+	R         string             `json:"r"`
+	S         string             `json:"s"`
+	Signature string             `json:"signature"`
+	TypedData apitypes.TypedData `json:"typedData"`
+}
+
+var permitCmd = &cobra.Command{
+	Use:   "permit <token> <spender> <amount|max>",
+	Short: "Sign an EIP-2612 permit so a relayer can submit the approval without gas",
+	Long: `Sign an EIP-2612 permit with the --from account. Nothing is sent to the chain: the
+signature (v, r, s) and the signed typed data are printed as JSON for a relayer to submit.
+The deadline is a duration from now (e.g. 30m, 24h) or a unix timestamp.`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		spender := common.HexToAddress(resolveAddress(args[1]))
+
+		deadline, err := parseDeadline(permitDeadline)
+		if err != nil {
+			utils.Log.Fatalf("Invalid deadline: %v", err)
+		}
+
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+		fromAccount := senderAccount(svc)
+
+		client := newTokenClient()
+		defer client.Close()
+
+		info, err := client.GetTokenInfo(args[0])
+		if err != nil {
+			utils.Log.Fatalf("Failed to get token info: %v", err)
+		}
+
+		amount := math.MaxBig256
+		if !strings.EqualFold(args[2], "max") {
+			amount, err = units.ParseUnits(args[2], int(info.Decimals))
+			if err != nil {
+				utils.Log.Fatalf("Invalid amount: %v", err)
+			}
+		}
+
+		typedData, err := client.BuildPermit(info, fromAccount.Address, spender, amount, deadline)
+		if err != nil {
+			utils.Log.Fatalf("Failed to build permit: %v", err)
+		}
+
+		// Confirm Signature
+		fmt.Printf("\n⚠️  CONFIRM PERMIT SIGNATURE\n")
+		fmt.Printf("Chain:     %s\n", tokenChain)
+		fmt.Printf("Owner:     %s\n", fromAccount.Address.Hex())
+		fmt.Printf("Token:     %s (%s)\n", tokenSymbol(info), info.Address.Hex())
+		fmt.Printf("Spender:   %s\n", spender.Hex())
+		fmt.Printf("Value:     %s\n", formatAllowance(amount, info))
+		fmt.Printf("Nonce:     %s\n", typedData.Message["nonce"])
+		fmt.Printf("Deadline:  %s (%s)\n", deadline, time.Unix(deadline.Int64(), 0).Format(time.RFC3339))
+		fmt.Println(strings.Repeat("-", 40))
+		fmt.Println("ℹ️  Anyone holding this signature can submit it and set the allowance.")
+
+		if amount.Cmp(math.MaxBig256) == 0 {
+			fmt.Printf("🚨 UNLIMITED APPROVAL: the spender will be able to transfer ALL of your %s,\n", tokenSymbol(info))
+			fmt.Println("   now and in the future, until you revoke it. Only do this for contracts you trust.")
+			if !confirm("Sign an unlimited permit?") {
+				utils.Log.Fatal("Aborted")
+			}
+		}
+
+		password := readSecret("Enter password to sign: ")
+		sig, err := svc.SignTypedData(fromAccount, typedData, password)
+		if err != nil {
+			utils.Log.Fatalf("Failed to sign permit: %v", err)
+		}
+
+		payload := permitPayload{
+			Token:     info.Address.Hex(),
+			ChainID:   client.ChainID.String(),
+			Owner:     fromAccount.Address.Hex(),
+			Spender:   spender.Hex(),
+			Value:     amount.String(),
+			Nonce:     fmt.Sprint(typedData.Message["nonce"]),
+			Deadline:  deadline.String(),
+			V:         sig[64],
+			R:         hexutil.Encode(sig[:32]),
+			S:         hexutil.Encode(sig[32:64]),
+			Signature: hexutil.Encode(sig),
+			TypedData: typedData,
+		}
+		data, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			utils.Log.Fatalf("Failed to encode permit: %v", err)
+		}
+
+		if permitOutput == "" {
+			fmt.Printf("\n✅ Permit signed!\n%s\n", data)
+			return
+		}
+		// The signature authorizes spending; never overwrite an existing file
+		f, err := os.OpenFile(permitOutput, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			utils.Log.Fatalf("Failed to create output file: %v", err)
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			f.Close()
+			utils.Log.Fatalf("Failed to write output file: %v", err)
+		}
+		if err := f.Close(); err != nil {
+			utils.Log.Fatalf("Failed to write output file: %v", err)
+		}
+		fmt.Printf("\n✅ Permit signed!\nv: %d\nr: %s\ns: %s\nSaved to %s\n", payload.V, payload.R, payload.S, permitOutput)
+	},
+}
+
+// parseDeadline accepts a duration from now ("30m", "24h") or a unix timestamp
+func parseDeadline(s string) (*big.Int, error) {
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if ts <= time.Now().Unix() {
+			return nil, fmt.Errorf("%s is in the past", s)
+		}
+		return big.NewInt(ts), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, fmt.Errorf("expected a duration like 30m or a unix timestamp: %s", s)
+	}
+	if d <= 0 {
+		return nil, fmt.Errorf("duration must be positive: %s", s)
+	}
+	return big.NewInt(time.Now().Add(d).Unix()), nil
+}
+
+func init() {
+	tokenCmd.AddCommand(permitCmd)
+	permitCmd.Flags().StringVar(&permitDeadline, "deadline", "1h", "permit expiry as a duration from now or a unix timestamp")
+	permitCmd.Flags().StringVarP(&permitOutput, "output", "o", "", "write the signed payload to this file (0600) instead of stdout")
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"tokit/internal/utils"
+
+	"golang.org/x/term"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// readSecret prompts for input without echoing it to the terminal
+func readSecret(prompt string) string {
+	fmt.Print(prompt)
+	byteInput, err := term.ReadPassword(int(syscall.Stdin))
+	if err != nil {
+		utils.Log.Fatalf("Failed to read input: %v", err)
+	}
+	fmt.Println()
+	return string(byteInput)
+}
+
+// readLine prompts for a single visible line of input
+func readLine(prompt string) string {
+	fmt.Print(prompt)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		utils.Log.Fatalf("Failed to read input: %v", err)
+	}
+	return strings.TrimSpace(line)
+}
+
+// confirm asks a yes/no question, defaulting to no
+func confirm(prompt string) bool {
+	answer := strings.ToLower(readLine(prompt + " [y/N]: "))
+	return answer == "y" || answer == "yes"
+}
+
+// readPassphrase prompts for an optional BIP39 passphrase; when confirmTwice is set
+// the passphrase has to be entered a second time
+func readPassphrase(confirmTwice bool) string {
+	passphrase := readSecret("Enter BIP39 passphrase: ")
+	if confirmTwice && passphrase != readSecret("Confirm BIP39 passphrase: ") {
+		utils.Log.Fatal("Passphrases do not match")
+	}
+	return passphrase
+}
@@ -4,16 +4,26 @@ import (
 	"fmt"
 	"os"
 
+	"tokit/internal/chain"
 	"tokit/internal/config"
+	"tokit/internal/units"
 	"tokit/internal/utils"
+	"tokit/internal/wallet"
 
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/cobra"
 )
 
 var (
-	cfgFile   string
-	Verbose   bool
-	AppConfig *config.Config
+	cfgFile     string
+	Verbose     bool
+	From        string
+	Speed       string
+	MaxFee      string
+	PriorityFee string
+	GasLimit    uint64
+	AppConfig   *config.Config
 )
 
 var rootCmd = &cobra.Command{
@@ -32,6 +42,86 @@ standard wallet operations like transfers and balance checks.`,
 	},
 }
 
+// senderAccount resolves the --from flag, falling back to the first local account
+func senderAccount(svc *wallet.Service) accounts.Account {
+	if From != "" {
+		acc, err := svc.GetAccount(From)
+		if err != nil {
+			utils.Log.Fatalf("Failed to resolve --from: %v", err)
+		}
+		return acc
+	}
+
+	accountsList := svc.ListAccounts()
+	if len(accountsList) == 0 {
+		utils.Log.Fatal("No accounts found. Please create or import a wallet.")
+	}
+	return accountsList[0]
+}
+
+// resolveAddress accepts a hex address as is and resolves anything else as a local account
+// label or list index
+func resolveAddress(ref string) string {
+	if common.IsHexAddress(ref) {
+		return ref
+	}
+
+	svc, err := wallet.NewService()
+	if err != nil {
+		utils.Log.Fatalf("Failed to init wallet service: %v", err)
+	}
+	acc, err := svc.GetAccount(ref)
+	if err != nil {
+		utils.Log.Fatalf("Failed to resolve address: %v", err)
+	}
+	return acc.Address.Hex()
+}
+
+// feeOptions parses the --speed, --max-fee and --priority-fee flags
+func feeOptions() chain.FeeOptions {
+	opts := chain.FeeOptions{Speed: Speed}
+	var err error
+	if MaxFee != "" {
+		if opts.MaxFee, err = units.ParseUnits(MaxFee, units.GweiDecimals); err != nil {
+			utils.Log.Fatalf("Invalid --max-fee: %v", err)
+		}
+	}
+	if PriorityFee != "" {
+		if opts.PriorityFee, err = units.ParseUnits(PriorityFee, units.GweiDecimals); err != nil {
+			utils.Log.Fatalf("Invalid --priority-fee: %v", err)
+		}
+	}
+	return opts
+}
+
+// newClient connects to a network and applies the fee flags
+func newClient(chainName string) *chain.Client {
+	client, err := chain.NewClient(chainName, AppConfig)
+	if err != nil {
+		utils.Log.Fatalf("Failed to create client: %v", err)
+	}
+	client.Fees = feeOptions()
+	return client
+}
+
+// addFeeFlags registers --speed, --max-fee and --priority-fee on commands that send or
+// build transactions
+func addFeeFlags(cmds ...*cobra.Command) {
+	for _, c := range cmds {
+		c.Flags().StringVar(&Speed, "speed", "", "fee preset: slow, normal or fast (default: network's speed, else normal)")
+		c.Flags().StringVar(&MaxFee, "max-fee", "", "max fee per gas in gwei, overriding the preset")
+		c.Flags().StringVar(&PriorityFee, "priority-fee", "", "max priority fee (tip) per gas in gwei, overriding the preset")
+	}
+}
+
+// addGasLimitFlag registers --gas-limit on commands that send a single transaction; they
+// apply it with client.GasLimit = GasLimit
+func addGasLimitFlag(cmds ...*cobra.Command) {
+	for _, c := range cmds {
+		c.Flags().Uint64Var(&GasLimit, "gas-limit", 0, "gas limit, overriding the estimate")
+	}
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -41,4 +131,5 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&Verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVarP(&From, "from", "f", "", "account to use: address, label or index from 'wallet list' (default: first account)")
 }
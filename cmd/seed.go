@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"tokit/internal/utils"
+	"tokit/internal/wallet"
+
+	"github.com/spf13/cobra"
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Manage mnemonics stored in the encrypted seed vault",
+	Long: `Mnemonics saved by 'wallet create --save-seed' and 'wallet import --save-seed' are kept in ~/.tokit/seeds,
+encrypted with scrypt and AES-256-GCM, so more accounts can be derived later with
+'wallet derive --seed <fingerprint>'.`,
+}
+
+var seedListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored seeds",
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+
+		seeds, err := svc.ListSeeds()
+		if err != nil {
+			utils.Log.Fatalf("Failed to list seeds: %v", err)
+		}
+		if len(seeds) == 0 {
+			fmt.Println("No seeds stored.")
+			return
+		}
+
+		// Count the keystore accounts derived from each seed
+		derived := make(map[string]int)
+		for _, acc := range svc.ListAccounts() {
+			if meta := svc.AccountMeta(acc.Address); meta.Seed != "" {
+				derived[meta.Seed]++
+			}
+		}
+
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "Fingerprint\tLanguage\tAccounts\tLocation")
+		for _, seed := range seeds {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", seed.ID, seed.Language, derived[seed.ID], seed.Path)
+		}
+		w.Flush()
+	},
+}
+
+var seedExportOutput string
+
+var seedExportCmd = &cobra.Command{
+	Use:   "export <fingerprint>",
+	Short: "Show the mnemonic of a stored seed",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+
+		fmt.Println("⚠️  WARNING: YOU ARE ABOUT TO EXPORT A MNEMONIC PHRASE!")
+		fmt.Println(strings.Repeat("=", 60))
+		fmt.Println("Anyone who sees it can steal ALL funds of EVERY account derived from it.")
+		fmt.Println("Never paste it into a website, chat or email, and never share it with support staff.")
+		fmt.Println(strings.Repeat("=", 60))
+		fmt.Printf("Seed: %s\n\n", args[0])
+		if readLine(`Type "EXPORT" to continue: `) != "EXPORT" {
+			utils.Log.Fatal("Aborted")
+		}
+
+		password := readSecret("Enter password: ")
+		mnemonic, passphrase, err := svc.ExportSeed(args[0], password)
+		if err != nil {
+			utils.Log.Fatalf("Failed to export seed: %v", err)
+		}
+
+		output := mnemonic + "\n"
+		if passphrase != "" {
+			output += "BIP39 passphrase: " + passphrase + "\n"
+		}
+
+		if seedExportOutput == "" {
+			fmt.Printf("\n%s", output)
+			return
+		}
+
+		// Never overwrite an existing file
+		f, err := os.OpenFile(seedExportOutput, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			utils.Log.Fatalf("Failed to create output file: %v", err)
+		}
+		if _, err := f.WriteString(output); err != nil {
+			f.Close()
+			utils.Log.Fatalf("Failed to write output file: %v", err)
+		}
+		if err := f.Close(); err != nil {
+			utils.Log.Fatalf("Failed to write output file: %v", err)
+		}
+		fmt.Printf("\n✅ Exported seed %s to %s\n", args[0], seedExportOutput)
+	},
+}
+
+var seedRemoveCmd = &cobra.Command{
+	Use:   "remove <fingerprint>",
+	Short: "Delete a stored seed",
+	Long: `Delete a seed from the vault. Accounts already derived from it stay in the keystore,
+but deriving more of them will require typing the mnemonic again.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+
+		fmt.Printf("⚠️  You are about to delete seed %s from the vault.\n", args[0])
+		fmt.Println("Make sure the mnemonic is backed up elsewhere.")
+		if !confirm("Delete this seed?") {
+			utils.Log.Fatal("Aborted")
+		}
+
+		password := readSecret("Enter password: ")
+		if err := svc.DeleteSeed(args[0], password); err != nil {
+			utils.Log.Fatalf("Failed to delete seed: %v", err)
+		}
+		fmt.Printf("\n✅ Seed %s deleted.\n", args[0])
+	},
+}
+
+func init() {
+	walletCmd.AddCommand(seedCmd)
+	seedCmd.AddCommand(seedListCmd)
+	seedCmd.AddCommand(seedExportCmd)
+	seedCmd.AddCommand(seedRemoveCmd)
+
+	seedExportCmd.Flags().StringVarP(&seedExportOutput, "output", "o", "", "write to this file (0600) instead of stdout")
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"tokit/internal/utils"
+	"tokit/internal/wallet"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	messageFile string
+	messageHex  string
+)
+
+var signCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Sign off-chain data with a keystore account",
+}
+
+var signMessageCmd = &cobra.Command{
+	Use:   "message [\"<text>\"]",
+	Short: "Sign a message as an EIP-191 personal signature (personal_sign)",
+	Long: `Sign a message with the --from account. The message is given as text, read from
+a file with --file, or given as raw bytes with --hex.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		msg := readMessage(args)
+
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+		fromAccount := senderAccount(svc)
+
+		// Confirm Signature
+		fmt.Printf("\n⚠️  CONFIRM MESSAGE SIGNATURE\n")
+		fmt.Printf("Signer:  %s\n", fromAccount.Address.Hex())
+		fmt.Println(strings.Repeat("-", 40))
+		fmt.Println(displayMessage(msg))
+		fmt.Println(strings.Repeat("-", 40))
+		if !confirm("Sign this message?") {
+			utils.Log.Fatal("Aborted")
+		}
+
+		password := readSecret("Enter password to sign: ")
+		sig, err := svc.SignMessage(fromAccount, msg, password)
+		if err != nil {
+			utils.Log.Fatalf("Failed to sign message: %v", err)
+		}
+
+		fmt.Printf("\n✅ Signed!\nAddress:   %s\nSignature: %s\n", fromAccount.Address.Hex(), hexutil.Encode(sig))
+	},
+}
+
+// displayMessage returns the message as text if it is printable, quoted with escapes if
+// it contains control characters (which could rewrite the terminal and spoof the prompt),
+// or as hex if it is not UTF-8
+func displayMessage(msg []byte) string {
+	if !utf8.Valid(msg) {
+		return hexutil.Encode(msg)
+	}
+	for _, r := range string(msg) {
+		if r != '\n' && !unicode.IsPrint(r) {
+			return fmt.Sprintf("%q", msg)
+		}
+	}
+	return string(msg)
+}
+
+// displayText returns a single-line value, such as a typed data field, as text if it is
+// printable and quoted with escapes otherwise
+func displayText(s string) string {
+	if !utf8.ValidString(s) || strings.IndexFunc(s, func(r rune) bool { return !unicode.IsPrint(r) }) >= 0 {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// readMessage returns the message given as an argument, with --file or with --hex
+func readMessage(args []string) []byte {
+	given := 0
+	if len(args) > 0 {
+		given++
+	}
+	if messageFile != "" {
+		given++
+	}
+	if messageHex != "" {
+		given++
+	}
+	if given != 1 {
+		utils.Log.Fatal("Give the message as text, with --file or with --hex")
+	}
+
+	switch {
+	case messageFile != "":
+		msg, err := os.ReadFile(messageFile)
+		if err != nil {
+			utils.Log.Fatalf("Failed to read message: %v", err)
+		}
+		return msg
+	case messageHex != "":
+		msg, err := hexutil.Decode(messageHex)
+		if err != nil {
+			utils.Log.Fatalf("Invalid hex message: %v", err)
+		}
+		return msg
+	default:
+		return []byte(args[0])
+	}
+}
+
+var signTypedDataCmd = &cobra.Command{
+	Use:   "typed-data <file.json>",
+	Short: "Sign EIP-712 typed data (eth_signTypedData_v4) with the --from account",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		typedData := readTypedData(args[0])
+		hash, _, err := apitypes.TypedDataAndHash(typedData)
+		if err != nil {
+			utils.Log.Fatalf("Invalid typed data: %v", err)
+		}
+
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+		fromAccount := senderAccount(svc)
+
+		// Confirm Signature
+		fmt.Printf("\n⚠️  CONFIRM TYPED DATA SIGNATURE\n")
+		fmt.Printf("Signer:  %s\n", fromAccount.Address.Hex())
+		fmt.Printf("Hash:    %s\n", hexutil.Encode(hash))
+		fmt.Println(strings.Repeat("-", 40))
+		printTypedData(typedData)
+		fmt.Println(strings.Repeat("-", 40))
+		if !confirm("Sign this data?") {
+			utils.Log.Fatal("Aborted")
+		}
+
+		password := readSecret("Enter password to sign: ")
+		sig, err := svc.SignTypedData(fromAccount, typedData, password)
+		if err != nil {
+			utils.Log.Fatalf("Failed to sign typed data: %v", err)
+		}
+
+		fmt.Printf("\n✅ Signed!\nSignature: %s\n", hexutil.Encode(sig))
+	},
+}
+
+// readTypedData loads eth_signTypedData_v4 JSON from a file
+func readTypedData(path string) apitypes.TypedData {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		utils.Log.Fatalf("Failed to read typed data: %v", err)
+	}
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(data, &typedData); err != nil {
+		utils.Log.Fatalf("Invalid typed data JSON: %v", err)
+	}
+	return typedData
+}
+
+// printTypedData prints the domain and message of typed data field by field, following
+// the order of the type definitions
+func printTypedData(typedData apitypes.TypedData) {
+	domain := typedData.Domain
+	fmt.Println("Domain:")
+	if domain.Name != "" {
+		fmt.Printf("  name: %s\n", displayText(domain.Name))
+	}
+	if domain.Version != "" {
+		fmt.Printf("  version: %s\n", displayText(domain.Version))
+	}
+	if domain.ChainId != nil {
+		fmt.Printf("  chainId: %s\n", (*big.Int)(domain.ChainId))
+	}
+	if domain.VerifyingContract != "" {
+		fmt.Printf("  verifyingContract: %s\n", displayText(domain.VerifyingContract))
+	}
+	if domain.Salt != "" {
+		fmt.Printf("  salt: %s\n", displayText(domain.Salt))
+	}
+
+	fmt.Printf("Message (%s):\n", displayText(typedData.PrimaryType))
+	printTypedStruct(typedData, typedData.PrimaryType, typedData.Message, "  ")
+}
+
+func printTypedStruct(typedData apitypes.TypedData, typeName string, data map[string]interface{}, indent string) {
+	for _, field := range typedData.Types[typeName] {
+		value := data[field.Name]
+		baseType := strings.Split(field.Type, "[")[0]
+		if _, isStruct := typedData.Types[baseType]; !isStruct {
+			fmt.Printf("%s%s: %s\n", indent, displayText(field.Name), formatTypedValue(value))
+			continue
+		}
+
+		fmt.Printf("%s%s (%s):\n", indent, displayText(field.Name), displayText(field.Type))
+		switch v := value.(type) {
+		case map[string]interface{}:
+			printTypedStruct(typedData, baseType, v, indent+"  ")
+		case []interface{}:
+			for i, item := range v {
+				fmt.Printf("%s  [%d]:\n", indent, i)
+				if m, ok := item.(map[string]interface{}); ok {
+					printTypedStruct(typedData, baseType, m, indent+"    ")
+				}
+			}
+		}
+	}
+}
+
+func formatTypedValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatTypedValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return displayText(fmt.Sprint(v))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(signCmd)
+	signCmd.AddCommand(signMessageCmd)
+	signCmd.AddCommand(signTypedDataCmd)
+
+	signMessageCmd.Flags().StringVar(&messageFile, "file", "", "read the message from this file")
+	signMessageCmd.Flags().StringVar(&messageHex, "hex", "", "message as 0x-prefixed hex bytes")
+}
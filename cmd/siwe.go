@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"tokit/internal/config"
+	"tokit/internal/siwe"
+	"tokit/internal/utils"
+	"tokit/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	siweChain  string
+	siweDomain string
+	siweNonce  string
+	siweAt     string
+)
+
+var siweCmd = &cobra.Command{
+	Use:   "siwe",
+	Short: "Sign and verify Sign-In with Ethereum (EIP-4361) messages",
+	Long: `Sign and verify Sign-In with Ethereum (EIP-4361) messages. Messages are read from a
+file, or from stdin when the file is "-". A trailing newline is ignored.`,
+}
+
+var siweSignCmd = &cobra.Command{
+	Use:   "sign <message-file>",
+	Short: "Check and sign a Sign-In with Ethereum message",
+	Long: `Parse a Sign-In with Ethereum message, check that it is for --domain, for the chain ID
+of --chain, not expired and (with --nonce) carries the expected nonce, then sign it with
+the account named in the message.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if siweDomain == "" {
+			utils.Log.Fatal("--domain is required: give the domain of the site you are signing in to")
+		}
+
+		text := readSIWEMessage(args[0])
+		msg, err := siwe.Parse(text)
+		if err != nil {
+			utils.Log.Fatalf("Invalid SIWE message: %v", err)
+		}
+
+		chainName, network := siweNetwork()
+		printSIWEMessage(msg)
+		if err := msg.Validate(siwe.Expectations{Domain: siweDomain, Nonce: siweNonce, ChainID: network.ChainID}); err != nil {
+			utils.Log.Fatalf("Refusing to sign: %v", err)
+		}
+
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+		// Sign with the account the message names unless --from picks one
+		if From == "" {
+			From = msg.Address.Hex()
+		}
+		fromAccount := senderAccount(svc)
+		if fromAccount.Address != msg.Address {
+			utils.Log.Fatalf("Message is for %s, but the signing account is %s", msg.Address.Hex(), fromAccount.Address.Hex())
+		}
+
+		fmt.Println(strings.Repeat("-", 40))
+		fmt.Printf("✅ Domain %s, chain %d (%s) and validity checked.\n", msg.Domain, msg.ChainID, chainName)
+		if siweNonce == "" {
+			fmt.Println("ℹ️  Nonce not checked (no --nonce given).")
+		}
+		if !confirm(fmt.Sprintf("Sign in to %s as %s?", msg.Domain, msg.Address.Hex())) {
+			utils.Log.Fatal("Aborted")
+		}
+
+		password := readSecret("Enter password to sign: ")
+		sig, err := svc.SignMessage(fromAccount, []byte(text), password)
+		if err != nil {
+			utils.Log.Fatalf("Failed to sign message: %v", err)
+		}
+
+		fmt.Printf("\n✅ Signed!\nSignature: %s\n", hexutil.Encode(sig))
+	},
+}
+
+var siweVerifyCmd = &cobra.Command{
+	Use:   "verify <message-file> <signature>",
+	Short: "Verify a signed Sign-In with Ethereum message",
+	Long: `Parse a Sign-In with Ethereum message, check that the signature was made by the address
+in the message and that the message is valid. Domain, nonce and chain ID are only checked
+when --domain, --nonce or --chain is given; --at checks the validity window at another
+time, e.g. for test fixtures.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		text := readSIWEMessage(args[0])
+		msg, err := siwe.Parse(text)
+		if err != nil {
+			utils.Log.Fatalf("Invalid SIWE message: %v", err)
+		}
+		printSIWEMessage(msg)
+		fmt.Println(strings.Repeat("-", 40))
+
+		want := siwe.Expectations{Domain: siweDomain, Nonce: siweNonce}
+		if siweChain != "" {
+			_, network := siweNetwork()
+			want.ChainID = network.ChainID
+		}
+		if siweAt != "" {
+			if want.Time, err = time.Parse(time.RFC3339, siweAt); err != nil {
+				utils.Log.Fatalf("Invalid --at time: %v", err)
+			}
+		}
+
+		signer, err := wallet.RecoverMessage([]byte(text), decodeSignature(args[1]))
+		if err != nil {
+			utils.Log.Fatalf("Failed to recover signer: %v", err)
+		}
+		fmt.Printf("Signer: %s\n", signer.Hex())
+		if signer != msg.Address {
+			utils.Log.Fatalf("❌ Signature was not made by %s", msg.Address.Hex())
+		}
+		if err := msg.Validate(want); err != nil {
+			utils.Log.Fatalf("❌ Invalid message: %v", err)
+		}
+		fmt.Println("✅ Signature and message are valid")
+	},
+}
+
+// readSIWEMessage reads a message from a file or stdin, normalizing line endings
+func readSIWEMessage(path string) string {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		utils.Log.Fatalf("Failed to read message: %v", err)
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	return strings.TrimRight(text, "\n")
+}
+
+// siweNetwork returns the --chain network, or the default one
+func siweNetwork() (string, config.NetworkConfig) {
+	name := siweChain
+	if name == "" {
+		name = AppConfig.Default
+	}
+	network, ok := AppConfig.Networks[name]
+	if !ok {
+		utils.Log.Fatalf("Network configuration not found for: %s", name)
+	}
+	return name, network
+}
+
+func printSIWEMessage(msg *siwe.Message) {
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+	domain := msg.Domain
+	if msg.Scheme != "" {
+		domain = msg.Scheme + "://" + domain
+	}
+	fmt.Fprintf(w, "Domain:\t%s\n", domain)
+	fmt.Fprintf(w, "Address:\t%s\n", msg.Address.Hex())
+	if msg.Statement != "" {
+		fmt.Fprintf(w, "Statement:\t%s\n", msg.Statement)
+	}
+	fmt.Fprintf(w, "URI:\t%s\n", msg.URI)
+	fmt.Fprintf(w, "Chain ID:\t%d\n", msg.ChainID)
+	fmt.Fprintf(w, "Nonce:\t%s\n", msg.Nonce)
+	fmt.Fprintf(w, "Issued At:\t%s\n", msg.IssuedAt.Format(time.RFC3339))
+	if msg.ExpirationTime != nil {
+		fmt.Fprintf(w, "Expires:\t%s\n", msg.ExpirationTime.Format(time.RFC3339))
+	}
+	if msg.NotBefore != nil {
+		fmt.Fprintf(w, "Not Before:\t%s\n", msg.NotBefore.Format(time.RFC3339))
+	}
+	if msg.RequestID != "" {
+		fmt.Fprintf(w, "Request ID:\t%s\n", msg.RequestID)
+	}
+	for _, resource := range msg.Resources {
+		fmt.Fprintf(w, "Resource:\t%s\n", resource)
+	}
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(siweCmd)
+	siweCmd.AddCommand(siweSignCmd)
+	siweCmd.AddCommand(siweVerifyCmd)
+
+	siweCmd.PersistentFlags().StringVarP(&siweChain, "chain", "c", "", "network whose chain ID the message must use (sign default: default_network from config)")
+	siweCmd.PersistentFlags().StringVar(&siweDomain, "domain", "", "domain the message must be for")
+	siweCmd.PersistentFlags().StringVar(&siweNonce, "nonce", "", "nonce the message must carry")
+	siweVerifyCmd.Flags().StringVar(&siweAt, "at", "", "check expiry and not-before at this RFC 3339 time instead of now")
+}
@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"tokit/internal/chain"
+	"tokit/internal/units"
+	"tokit/internal/utils"
+	"tokit/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tokenChain   string
+	approveReset bool
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage ERC20 token allowances",
+}
+
+var allowanceCmd = &cobra.Command{
+	Use:   "allowance <token> <owner> <spender>",
+	Short: "Show how many tokens a spender may transfer on behalf of an owner",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		owner := resolveAddress(args[1])
+		spender := resolveAddress(args[2])
+
+		client := newTokenClient()
+		defer client.Close()
+
+		info, err := client.GetTokenInfo(args[0])
+		if err != nil {
+			utils.Log.Fatalf("Failed to get token info: %v", err)
+		}
+		allowance, err := client.GetAllowance(args[0], owner, spender)
+		if err != nil {
+			utils.Log.Fatalf("Failed to get allowance: %v", err)
+		}
+
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "Token\tOwner\tSpender\tAllowance")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", tokenSymbol(info), owner, spender, formatAllowance(allowance, info))
+		w.Flush()
+	},
+}
+
+var approveCmd = &cobra.Command{
+	Use:   "approve <token> <spender> <amount|max>",
+	Short: "Allow a spender to transfer tokens from the --from account",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		spender := resolveAddress(args[1])
+
+		client := newTokenClient()
+		defer client.Close()
+		client.GasLimit = GasLimit
+
+		info, err := client.GetTokenInfo(args[0])
+		if err != nil {
+			utils.Log.Fatalf("Failed to get token info: %v", err)
+		}
+
+		amount := math.MaxBig256
+		if !strings.EqualFold(args[2], "max") {
+			amount, err = units.ParseUnits(args[2], int(info.Decimals))
+			if err != nil {
+				utils.Log.Fatalf("Invalid amount: %v", err)
+			}
+		}
+
+		sendApprove(client, info, spender, amount)
+	},
+}
+
+var revokeCmd = &cobra.Command{
+	Use:   "revoke <token> <spender>",
+	Short: "Set a spender's allowance over the --from account's tokens to zero",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		spender := resolveAddress(args[1])
+
+		client := newTokenClient()
+		defer client.Close()
+		client.GasLimit = GasLimit
+
+		info, err := client.GetTokenInfo(args[0])
+		if err != nil {
+			utils.Log.Fatalf("Failed to get token info: %v", err)
+		}
+
+		sendApprove(client, info, spender, big.NewInt(0))
+	},
+}
+
+var (
+	scanStartBlock uint64
+	scanEndBlock   uint64
+	scanBlocks     uint64
+	scanChunkSize  uint64
+	scanRevoke     bool
+)
+
+var approvalsCmd = &cobra.Command{
+	Use:   "approvals <address>",
+	Short: "Find all outstanding token approvals of an address",
+	Long: `Scan Approval events emitted for the address over a block range and re-check the current
+allowance of every token/spender pair, listing the ones that are still live.
+Without --start-block, the last --blocks blocks are scanned. With --revoke, the chosen
+approvals are revoked (the address must be a local account).`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		owner := common.HexToAddress(resolveAddress(args[0]))
+
+		client := newTokenClient()
+		defer client.Close()
+
+		endBlock := scanEndBlock
+		if endBlock == 0 {
+			latest, err := client.BlockNumber()
+			if err != nil {
+				utils.Log.Fatalf("Failed to get latest block: %v", err)
+			}
+			endBlock = latest
+		}
+		startBlock := scanStartBlock
+		if !cmd.Flags().Changed("start-block") {
+			startBlock = 0
+			if endBlock >= scanBlocks {
+				startBlock = endBlock - scanBlocks + 1
+			}
+		}
+		if startBlock > endBlock {
+			utils.Log.Fatalf("Start block %d is after end block %d", startBlock, endBlock)
+		}
+
+		fmt.Printf("Scanning %s for approvals by %s (blocks %d-%d)...\n", tokenChain, owner.Hex(), startBlock, endBlock)
+		pairs, err := client.FindApprovals(owner, startBlock, endBlock, scanChunkSize, func(scannedTo uint64) {
+			utils.Log.Debugf("Scanned up to block %d", scannedTo)
+		})
+		if err != nil {
+			utils.Log.Fatalf("Failed to scan approvals: %v", err)
+		}
+
+		type liveApproval struct {
+			pair      chain.ApprovalPair
+			info      *chain.TokenInfo
+			allowance *big.Int
+		}
+		var live []liveApproval
+		for _, pair := range pairs {
+			allowance, err := client.GetAllowance(pair.Token.Hex(), owner.Hex(), pair.Spender.Hex())
+			if err != nil {
+				utils.Log.Warnf("Failed to get allowance of %s for %s: %v", pair.Token.Hex(), pair.Spender.Hex(), err)
+				continue
+			}
+			if allowance.Sign() == 0 {
+				continue
+			}
+			info, err := client.GetTokenInfo(pair.Token.Hex())
+			if err != nil {
+				// Not a regular ERC20; show the raw allowance
+				info = &chain.TokenInfo{Address: pair.Token}
+			}
+			live = append(live, liveApproval{pair: pair, info: info, allowance: allowance})
+		}
+
+		if len(live) == 0 {
+			fmt.Println("No live approvals found.")
+			return
+		}
+
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "#\tToken\tToken Address\tSpender\tAllowance\tLast Approval Block")
+		for i, a := range live {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\n", i+1, tokenSymbol(a.info), a.pair.Token.Hex(),
+				a.pair.Spender.Hex(), formatAllowance(a.allowance, a.info), a.pair.Block)
+		}
+		w.Flush()
+
+		if !scanRevoke {
+			return
+		}
+
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+		fromAccount, err := svc.GetAccount(owner.Hex())
+		if err != nil {
+			utils.Log.Fatalf("Cannot revoke: %v", err)
+		}
+
+		selected, err := parseSelection(readLine("\nApprovals to revoke (e.g. 1,3-5 or all): "), len(live))
+		if err != nil {
+			utils.Log.Fatalf("Invalid selection: %v", err)
+		}
+		if len(selected) == 0 {
+			fmt.Println("Nothing selected.")
+			return
+		}
+
+		fmt.Printf("\n⚠️  CONFIRM REVOKE (%d approvals, one transaction each)\n", len(selected))
+		for _, i := range selected {
+			fmt.Printf("  #%d  %s  →  %s\n", i+1, tokenSymbol(live[i].info), live[i].pair.Spender.Hex())
+		}
+		fmt.Println(strings.Repeat("-", 40))
+
+		password := readSecret("Enter password to confirm: ")
+		signFn := passwordSigner(svc, password)
+
+		failed := 0
+		for _, i := range selected {
+			a := live[i]
+			fmt.Printf("\nRevoking #%d (%s → %s)...\n", i+1, tokenSymbol(a.info), a.pair.Spender.Hex())
+			txHash, err := client.Approve(fromAccount, a.pair.Token.Hex(), a.pair.Spender.Hex(), big.NewInt(0), signFn)
+			if err != nil {
+				failed++
+				utils.Log.Errorf("Failed to revoke #%d: %v", i+1, err)
+				continue
+			}
+			printTxSent(client, txHash)
+		}
+		if failed > 0 {
+			utils.Log.Fatalf("%d revoke transaction(s) failed", failed)
+		}
+	},
+}
+
+// parseSelection parses a 1-based selection like "1,3-5" or "all" into 0-based indexes
+func parseSelection(input string, n int) ([]int, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, nil
+	}
+
+	seen := make(map[int]bool)
+	var selected []int
+	add := func(i int) {
+		if !seen[i] {
+			seen[i] = true
+			selected = append(selected, i)
+		}
+	}
+
+	if strings.EqualFold(input, "all") {
+		for i := 0; i < n; i++ {
+			add(i)
+		}
+		return selected, nil
+	}
+
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil {
+			return nil, fmt.Errorf("not a number: %s", part)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+				return nil, fmt.Errorf("not a number: %s", part)
+			}
+		}
+		if first < 1 || last > n || first > last {
+			return nil, fmt.Errorf("out of range: %s (1-%d)", part, n)
+		}
+		for i := first; i <= last; i++ {
+			add(i - 1)
+		}
+	}
+	return selected, nil
+}
+
+// sendApprove confirms and sends an approve transaction from the --from account, resetting
+// the allowance to zero first for tokens that require it
+func sendApprove(client *chain.Client, info *chain.TokenInfo, spender string, amount *big.Int) {
+	svc, err := wallet.NewService()
+	if err != nil {
+		utils.Log.Fatalf("Failed to init wallet service: %v", err)
+	}
+	fromAccount := senderAccount(svc)
+	token := info.Address.Hex()
+
+	current, err := client.GetAllowance(token, fromAccount.Address.Hex(), spender)
+	if err != nil {
+		utils.Log.Fatalf("Failed to get allowance: %v", err)
+	}
+	if current.Cmp(amount) == 0 {
+		fmt.Printf("Allowance is already %s\n", formatAllowance(current, info))
+		return
+	}
+
+	needsReset := approveReset && current.Sign() > 0 && amount.Sign() > 0
+	if !needsReset {
+		needsReset, err = client.ApproveNeedsReset(fromAccount.Address, token, spender, amount)
+		if err != nil {
+			utils.Log.Fatalf("Failed to simulate approve: %v", err)
+		}
+	}
+
+	// Confirm Transaction
+	fmt.Printf("\n⚠️  CONFIRM APPROVAL\n")
+	fmt.Printf("Chain:    %s\n", tokenChain)
+	fmt.Printf("Owner:    %s\n", fromAccount.Address.Hex())
+	fmt.Printf("Token:    %s (%s)\n", tokenSymbol(info), token)
+	fmt.Printf("Spender:  %s\n", spender)
+	fmt.Printf("Current:  %s\n", formatAllowance(current, info))
+	fmt.Printf("New:      %s\n", formatAllowance(amount, info))
+	fmt.Println(strings.Repeat("-", 40))
+
+	if amount.Cmp(math.MaxBig256) == 0 {
+		fmt.Printf("🚨 UNLIMITED APPROVAL: the spender will be able to transfer ALL of your %s,\n", tokenSymbol(info))
+		fmt.Println("   now and in the future, until you revoke it. Only do this for contracts you trust.")
+		if !confirm("Grant an unlimited approval?") {
+			utils.Log.Fatal("Aborted")
+		}
+	}
+	if needsReset {
+		fmt.Println("ℹ️  This token rejects changing a non-zero allowance directly.")
+		fmt.Println("   The allowance will be reset to 0 first, so two transactions will be sent;")
+		fmt.Println("   the second one once the first is mined.")
+	}
+
+	password := readSecret("Enter password to confirm: ")
+	signFn := passwordSigner(svc, password)
+
+	var txHash string
+	if needsReset {
+		fmt.Println("\nResetting allowance to 0...")
+		txHash, err = client.ApproveWithReset(fromAccount, token, spender, amount, signFn, func(resetHash string) {
+			printTxSent(client, resetHash)
+			fmt.Println("\nWaiting for the reset to be mined before sending the approval...")
+		})
+	} else {
+		fmt.Println("\nSending transaction...")
+		txHash, err = client.Approve(fromAccount, token, spender, amount, signFn)
+	}
+	if err != nil {
+		utils.Log.Fatalf("Failed to send transaction: %v", err)
+	}
+	printTxSent(client, txHash)
+}
+
+func newTokenClient() *chain.Client {
+	if tokenChain == "" {
+		tokenChain = AppConfig.Default
+	}
+	return newClient(tokenChain)
+}
+
+func formatAllowance(allowance *big.Int, info *chain.TokenInfo) string {
+	if allowance.Cmp(math.MaxBig256) == 0 {
+		return "unlimited"
+	}
+	return units.FormatUnits(allowance, int(info.Decimals)) + " " + tokenSymbol(info)
+}
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.AddCommand(allowanceCmd)
+	tokenCmd.AddCommand(approveCmd)
+	tokenCmd.AddCommand(revokeCmd)
+	tokenCmd.AddCommand(approvalsCmd)
+
+	tokenCmd.PersistentFlags().StringVarP(&tokenChain, "chain", "c", "", "network to use (default: default_network from config)")
+	approveCmd.Flags().BoolVar(&approveReset, "reset", false, "always reset a non-zero allowance to 0 before approving")
+
+	approvalsCmd.Flags().Uint64Var(&scanStartBlock, "start-block", 0, "first block to scan (default: latest minus --blocks)")
+	approvalsCmd.Flags().Uint64Var(&scanEndBlock, "end-block", 0, "last block to scan (default: latest)")
+	approvalsCmd.Flags().Uint64Var(&scanBlocks, "blocks", 100000, "number of recent blocks to scan when --start-block is not set")
+	approvalsCmd.Flags().Uint64Var(&scanChunkSize, "chunk-size", 5000, "blocks per eth_getLogs request")
+	approvalsCmd.Flags().BoolVar(&scanRevoke, "revoke", false, "interactively revoke the approvals found")
+	addFeeFlags(approveCmd, revokeCmd, approvalsCmd)
+	addGasLimitFlag(approveCmd, revokeCmd)
+}
@@ -4,19 +4,24 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
-	"syscall"
+	"time"
 
 	"tokit/internal/chain"
+	"tokit/internal/units"
 	"tokit/internal/utils"
 	"tokit/internal/wallet"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 )
 
-var transferTokenAddress string
+var (
+	transferTokenAddress string
+	transferWait         bool
+	transferConfirms     uint64
+	transferWaitTimeout  time.Duration
+)
 
 var transferCmd = &cobra.Command{
 	Use:   "transfer [chain] [to] [amount]",
@@ -24,64 +29,80 @@ var transferCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(3),
 	Run: func(cmd *cobra.Command, args []string) {
 		chainName := args[0]
-		toAddress := args[1]
+		toAddress := resolveAddress(args[1])
 		amountStr := args[2]
 
-		// Parse amount
-		amount := new(big.Float)
-		_, ok := amount.SetString(amountStr)
-		if !ok {
-			utils.Log.Fatal("Invalid amount")
-		}
-
 		// Init Wallet Service
 		svc, err := wallet.NewService()
 		if err != nil {
 			utils.Log.Fatalf("Failed to init wallet service: %v", err)
 		}
 
-		// Get Sender Account (First one for now, or select via flag later)
-		accountsList := svc.ListAccounts()
-		if len(accountsList) == 0 {
-			utils.Log.Fatal("No accounts found. Please create or import a wallet.")
-		}
-		fromAccount := accountsList[0]
+		// Get Sender Account (--from, or the first one)
+		fromAccount := senderAccount(svc)
 
 		// Init Chain Client
-		client, err := chain.NewClient(chainName, AppConfig)
-		if err != nil {
-			utils.Log.Fatalf("Failed to create client: %v", err)
-		}
+		client := newClient(chainName)
 		defer client.Close()
+		client.GasLimit = GasLimit
 
 		symbol := client.Config.Symbol
+		decimals := units.EtherDecimals
 		if transferTokenAddress != "" {
-			symbol = "TOKEN"
+			info, err := client.GetTokenInfo(transferTokenAddress)
+			if err != nil {
+				utils.Log.Fatalf("Failed to get token info: %v", err)
+			}
+			symbol, decimals = tokenSymbol(info), int(info.Decimals)
+		}
+
+		// Parse amount into base units
+		amount, err := units.ParseUnits(amountStr, decimals)
+		if err != nil {
+			utils.Log.Fatalf("Invalid amount: %v", err)
 		}
 
+		// Estimate gas and fees
+		var gasLimit uint64
+		if transferTokenAddress != "" {
+			gasLimit, err = client.EstimateTokenTransferGas(fromAccount.Address, transferTokenAddress, toAddress, amount)
+		} else {
+			gasLimit, err = client.EstimateTransferGas(fromAccount.Address, toAddress, amount)
+		}
+		if err != nil {
+			utils.Log.Fatalf("Failed to estimate gas: %v", err)
+		}
+		fees, err := client.SuggestFees()
+		if err != nil {
+			utils.Log.Fatalf("Failed to get fees: %v", err)
+		}
+		maxCost := new(big.Int).Mul(fees.MaxFee, new(big.Int).SetUint64(gasLimit))
+
 		// Confirm Transaction
 		fmt.Printf("\n⚠️  CONFIRM TRANSACTION\n")
 		fmt.Printf("Chain:  %s\n", chainName)
 		fmt.Printf("From:   %s\n", fromAccount.Address.Hex())
 		fmt.Printf("To:     %s\n", toAddress)
-		fmt.Printf("Amount: %s %s\n", amountStr, symbol)
+		fmt.Printf("Amount: %s %s\n", units.FormatUnits(amount, decimals), symbol)
 		if transferTokenAddress != "" {
 			fmt.Printf("Token:  %s\n", transferTokenAddress)
 		}
+		if GasLimit != 0 {
+			fmt.Printf("Gas:    %d (--gas-limit)\n", gasLimit)
+		} else {
+			fmt.Printf("Gas:    %d (estimated)\n", gasLimit)
+		}
+		fmt.Printf("Fee:    max %s gwei/gas, up to %s %s\n", gwei(fees.MaxFee), units.FormatUnits(maxCost, units.EtherDecimals), client.Config.Symbol)
 		fmt.Println(strings.Repeat("-", 40))
 
-		fmt.Print("Enter password to confirm: ")
-		bytePassword, err := term.ReadPassword(int(syscall.Stdin))
-		if err != nil {
-			utils.Log.Fatalf("Failed to read password: %v", err)
-		}
-		password := string(bytePassword)
-		fmt.Println()
+		password := readSecret("Enter password to confirm: ")
+
+		// Send with the gas limit and fees that were confirmed
+		client.GasLimit = gasLimit
+		client.Fees.MaxFee, client.Fees.PriorityFee = fees.MaxFee, fees.PriorityFee
 
 		// Define Signer Function
-		signFn := func(a accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
-			return svc.SignTx(a, tx, chainID, password)
-		}
+		signFn := passwordSigner(svc, password)
 
 		// Send Transaction
 		fmt.Println("\nSending transaction...")
@@ -96,12 +117,66 @@ var transferCmd = &cobra.Command{
 			utils.Log.Fatalf("Failed to send transaction: %v", err)
 		}
 
-		fmt.Printf("\n✅ Transaction Sent!\nHash: %s\n", txHash)
-		fmt.Printf("Explorer: %s/tx/%s\n", client.Config.Explorer, txHash)
+		printTxSent(client, txHash)
+
+		if transferWait || transferConfirms > 0 {
+			waitForTx(client, txHash, transferConfirms, transferWaitTimeout)
+		}
 	},
 }
 
+// passwordSigner returns a signer that signs with the keystore account using password
+func passwordSigner(svc *wallet.Service, password string) chain.SignerFn {
+	return func(a accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return svc.SignTx(a, tx, chainID, password)
+	}
+}
+
+func printTxSent(client *chain.Client, txHash string) {
+	fmt.Printf("\n✅ Transaction Sent!\nHash: %s\n", txHash)
+	fmt.Printf("Explorer: %s/tx/%s\n", client.Config.Explorer, txHash)
+}
+
+// waitForTx waits until the transaction has the given number of confirmations, prints its
+// receipt and exits non-zero if it reverted, was dropped or replaced, or timed out
+func waitForTx(client *chain.Client, txHash string, confirmations uint64, timeout time.Duration) {
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	fmt.Printf("\nWaiting for %d confirmation(s)...\n", confirmations)
+	receipt, err := client.WaitForReceipt(txHash, confirmations, timeout, func(confirmed uint64) {
+		fmt.Printf("  %d/%d confirmations\n", confirmed, confirmations)
+	})
+	if err != nil {
+		utils.Log.Fatalf("Failed to wait for transaction: %v", err)
+	}
+
+	status := "✅ Success"
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		status = "❌ Reverted"
+	}
+
+	fmt.Printf("\nStatus:               %s\n", status)
+	fmt.Printf("Block:                %s\n", receipt.BlockNumber)
+	fmt.Printf("Gas Used:             %d\n", receipt.GasUsed)
+	// Some nodes omit effectiveGasPrice from receipts
+	if receipt.EffectiveGasPrice != nil {
+		fee := new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+		fmt.Printf("Effective Gas Price:  %s gwei\n", gwei(receipt.EffectiveGasPrice))
+		fmt.Printf("Total Fee:            %s %s\n", units.FormatUnits(fee, units.EtherDecimals), client.Config.Symbol)
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		utils.Log.Fatalf("Transaction %s reverted", txHash)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(transferCmd)
 	transferCmd.Flags().StringVarP(&transferTokenAddress, "token", "t", "", "ERC20 token address")
+	transferCmd.Flags().BoolVarP(&transferWait, "wait", "w", false, "wait until the transaction is mined and report its receipt")
+	transferCmd.Flags().Uint64Var(&transferConfirms, "confirmations", 0, "wait for this many confirmations (implies --wait)")
+	transferCmd.Flags().DurationVar(&transferWaitTimeout, "wait-timeout", 15*time.Minute, "give up waiting after this long (0 waits forever)")
+	addFeeFlags(transferCmd)
+	addGasLimitFlag(transferCmd)
 }
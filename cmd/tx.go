@@ -0,0 +1,408 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"tokit/internal/chain"
+	"tokit/internal/units"
+	"tokit/internal/utils"
+	"tokit/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	txBuildToken  string
+	txBuildOutput string
+	txSignOutput  string
+	txNonceReset  bool
+)
+
+var txCmd = &cobra.Command{
+	Use:   "tx",
+	Short: "Build, sign, broadcast and replace transactions",
+	Long: `Split a transfer into three steps so keys can stay on an offline machine:
+
+  tx build      (online)  fetch nonce and fees and write an unsigned transaction file
+  tx sign       (offline) sign the file with a keystore account, no RPC connection
+  tx broadcast  (online)  send the raw signed transaction
+
+Stuck transactions can be replaced with 'tx speedup' and 'tx cancel'.`,
+}
+
+var txBuildCmd = &cobra.Command{
+	Use:   "build [chain] [to] [amount]",
+	Short: "Build an unsigned transfer with the current nonce and fees",
+	Long: `Build an unsigned transfer from the --from address and write it to a JSON file. The
+--from address does not need to be in the local keystore.`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		chainName := args[0]
+		toAddress := resolveAddress(args[1])
+
+		from := From
+		if from == "" {
+			svc, err := wallet.NewService()
+			if err != nil {
+				utils.Log.Fatalf("Failed to init wallet service: %v", err)
+			}
+			from = senderAccount(svc).Address.Hex()
+		}
+		fromAddress := common.HexToAddress(resolveAddress(from))
+
+		client := newClient(chainName)
+		defer client.Close()
+		client.GasLimit = GasLimit
+
+		utx := &chain.UnsignedTx{Network: chainName, ChainID: client.ChainID, From: fromAddress}
+		decimals := units.EtherDecimals
+		var err error
+		if txBuildToken != "" {
+			utx.Token, err = client.GetTokenInfo(txBuildToken)
+			if err != nil {
+				utils.Log.Fatalf("Failed to get token info: %v", err)
+			}
+			decimals = int(utx.Token.Decimals)
+		}
+
+		amount, err := units.ParseUnits(args[2], decimals)
+		if err != nil {
+			utils.Log.Fatalf("Invalid amount: %v", err)
+		}
+
+		if utx.Token != nil {
+			utx.Tx, err = client.BuildTokenTransaction(fromAddress, txBuildToken, toAddress, amount)
+		} else {
+			utx.Tx, err = client.BuildTransaction(fromAddress, toAddress, amount)
+		}
+		if err != nil {
+			utils.Log.Fatalf("Failed to build transaction: %v", err)
+		}
+
+		printTxDetails(utx.Tx, utx.ChainID, fromAddress, client.Config.Symbol, utx.Token)
+
+		if err := chain.WriteUnsignedTx(txBuildOutput, utx); err != nil {
+			utils.Log.Fatalf("Failed to write unsigned transaction: %v", err)
+		}
+		fmt.Printf("\n✅ Unsigned transaction written to %s\n", txBuildOutput)
+		fmt.Printf("Sign it offline with: tokit tx sign %s\n", txBuildOutput)
+	},
+}
+
+var txSignCmd = &cobra.Command{
+	Use:   "sign <unsigned.json>",
+	Short: "Sign a transaction file offline",
+	Long: `Sign a transaction built by 'tx build' with the keystore account it was built for.
+No RPC connection is made, so this can run on an air-gapped machine.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		utx, err := chain.ReadUnsignedTx(args[0])
+		if err != nil {
+			utils.Log.Fatalf("Failed to read transaction: %v", err)
+		}
+
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+		fromAccount, err := svc.GetAccount(utx.From.Hex())
+		if err != nil {
+			utils.Log.Fatalf("Cannot sign: %v", err)
+		}
+		if From != "" && senderAccount(svc).Address != utx.From {
+			utils.Log.Fatalf("Transaction was built for %s, not --from %s", utx.From.Hex(), From)
+		}
+
+		symbol := "ETH"
+		if network, ok := AppConfig.Networks[utx.Network]; ok {
+			if network.ChainID != utx.ChainID.Int64() {
+				utils.Log.Fatalf("Chain ID mismatch: transaction has %s, network %s is %d", utx.ChainID, utx.Network, network.ChainID)
+			}
+			symbol = network.Symbol
+		}
+
+		// The token metadata is not signed and can't be checked offline; it must at least
+		// describe the contract the transaction calls
+		if utx.Token != nil && (utx.Tx.To() == nil || utx.Token.Address != *utx.Tx.To()) {
+			utils.Log.Fatalf("Token %s in the file is not the contract the transaction calls", utx.Token.Address.Hex())
+		}
+
+		fmt.Printf("\n⚠️  CONFIRM OFFLINE SIGNATURE\n")
+		fmt.Printf("Network:       %s\n", utx.Network)
+		printTxDetails(utx.Tx, utx.ChainID, utx.From, symbol, utx.Token)
+		if utx.Token != nil {
+			fmt.Println("⚠️  Token symbol and decimals come from the unsigned file and are UNVERIFIED.")
+			fmt.Println("   Check the raw amount and the token contract address above.")
+		}
+
+		password := readSecret("Enter password to sign: ")
+		signedTx, err := svc.SignTx(fromAccount, utx.Tx, utx.ChainID, password)
+		if err != nil {
+			utils.Log.Fatalf("Failed to sign transaction: %v", err)
+		}
+		raw, err := chain.EncodeSignedTx(signedTx)
+		if err != nil {
+			utils.Log.Fatalf("Failed to encode transaction: %v", err)
+		}
+
+		if txSignOutput == "" {
+			fmt.Printf("\n✅ Signed!\nHash: %s\n%s\n", signedTx.Hash().Hex(), raw)
+			return
+		}
+		// Never overwrite an existing file
+		f, err := os.OpenFile(txSignOutput, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			utils.Log.Fatalf("Failed to create output file: %v", err)
+		}
+		if _, err := f.WriteString(raw + "\n"); err != nil {
+			f.Close()
+			utils.Log.Fatalf("Failed to write signed transaction: %v", err)
+		}
+		if err := f.Close(); err != nil {
+			utils.Log.Fatalf("Failed to write signed transaction: %v", err)
+		}
+		fmt.Printf("\n✅ Signed!\nHash: %s\nRaw transaction written to %s\n", signedTx.Hash().Hex(), txSignOutput)
+	},
+}
+
+var txBroadcastCmd = &cobra.Command{
+	Use:   "broadcast [chain] <raw-tx|file>",
+	Short: "Broadcast a raw signed transaction",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		raw := args[1]
+		if !strings.HasPrefix(raw, "0x") {
+			data, err := os.ReadFile(raw)
+			if err != nil {
+				utils.Log.Fatalf("Failed to read signed transaction: %v", err)
+			}
+			raw = string(data)
+		}
+		tx, err := chain.DecodeSignedTx(raw)
+		if err != nil {
+			utils.Log.Fatal(err)
+		}
+
+		client, err := chain.NewClient(args[0], AppConfig)
+		if err != nil {
+			utils.Log.Fatalf("Failed to create client: %v", err)
+		}
+		defer client.Close()
+
+		if tx.ChainId().Cmp(client.ChainID) != 0 {
+			utils.Log.Fatalf("Chain ID mismatch: transaction is for chain %s, %s is %s", tx.ChainId(), args[0], client.ChainID)
+		}
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			utils.Log.Fatalf("Invalid signature: %v", err)
+		}
+
+		var info *chain.TokenInfo
+		if _, _, ok := chain.DecodeTokenTransfer(tx); ok {
+			info, _ = client.GetTokenInfo(tx.To().Hex())
+		}
+
+		fmt.Printf("\n⚠️  CONFIRM BROADCAST\n")
+		fmt.Printf("Hash:          %s\n", tx.Hash().Hex())
+		printTxDetails(tx, tx.ChainId(), from, client.Config.Symbol, info)
+		if !confirm("Broadcast this transaction?") {
+			utils.Log.Fatal("Aborted")
+		}
+
+		txHash, err := client.SendSignedTx(tx)
+		if err != nil {
+			utils.Log.Fatalf("Failed to broadcast transaction: %v", err)
+		}
+		printTxSent(client, txHash)
+	},
+}
+
+var txSpeedupCmd = &cobra.Command{
+	Use:   "speedup [chain] <hash>",
+	Short: "Resend a pending transaction with higher fees",
+	Long: `Replace a pending transaction with a copy using the same nonce and a tip and fee cap
+raised by at least 10%, as nodes require for replacements.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		replaceTx(args[0], args[1], false)
+	},
+}
+
+var txCancelCmd = &cobra.Command{
+	Use:   "cancel [chain] <hash>",
+	Short: "Cancel a pending transaction",
+	Long: `Replace a pending transaction with a 0-value transfer to yourself using the same nonce
+and a tip and fee cap raised by at least 10%. Whichever is mined first wins, so a
+transaction that is mined before the replacement cannot be cancelled.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		replaceTx(args[0], args[1], true)
+	},
+}
+
+var txNonceCmd = &cobra.Command{
+	Use:   "nonce [chain]",
+	Short: "Show the nonces tracked for the --from address",
+	Long: `Compare the nonces the node reports for the --from address with the ones tokit tracks
+for transactions still in flight. Transactions the node has lost leave a gap that blocks
+every later nonce; resend them, or use --reset to forget the tracked nonces and continue
+from the node's pending nonce.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		from := From
+		if from == "" {
+			svc, err := wallet.NewService()
+			if err != nil {
+				utils.Log.Fatalf("Failed to init wallet service: %v", err)
+			}
+			from = senderAccount(svc).Address.Hex()
+		}
+		address := common.HexToAddress(resolveAddress(from))
+
+		client, err := chain.NewClient(args[0], AppConfig)
+		if err != nil {
+			utils.Log.Fatalf("Failed to create client: %v", err)
+		}
+		defer client.Close()
+
+		if txNonceReset {
+			if err := client.ResetNonce(address); err != nil {
+				utils.Log.Fatalf("Failed to reset nonces: %v", err)
+			}
+			fmt.Printf("✅ Tracked nonces of %s on %s cleared.\n", address.Hex(), args[0])
+		}
+
+		status, err := client.NonceStatus(address)
+		if err != nil {
+			utils.Log.Fatalf("Failed to get nonces: %v", err)
+		}
+
+		fmt.Printf("Address:           %s\n", address.Hex())
+		fmt.Printf("Confirmed Nonce:   %d\n", status.Confirmed)
+		fmt.Printf("Node Pending:      %d\n", status.Pending)
+		fmt.Printf("Next (tokit):      %d\n", status.Next)
+
+		if len(status.InFlight) > 0 {
+			w := new(tabwriter.Writer)
+			w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+			fmt.Fprintln(w, "\nNonce\tHash\tStatus")
+			for _, tx := range status.InFlight {
+				state := "pending"
+				if !tx.Known {
+					state = "unknown to node"
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\n", tx.Nonce, tx.Hash, state)
+			}
+			w.Flush()
+		}
+
+		if status.Gap {
+			fmt.Println("\n⚠️  Nonce gap: the node lost transactions tokit sent, so later ones will not be mined.")
+			fmt.Printf("   Resend them, or run 'tokit tx nonce %s --reset' to continue from nonce %d.\n", args[0], status.Pending)
+		}
+	},
+}
+
+// replaceTx speeds up or cancels a pending transaction sent from a local account
+func replaceTx(chainName, txHash string, cancel bool) {
+	client := newClient(chainName)
+	defer client.Close()
+
+	pending, sender, err := client.PendingTransaction(txHash)
+	if err != nil {
+		utils.Log.Fatalf("Cannot replace %s: %v", txHash, err)
+	}
+
+	svc, err := wallet.NewService()
+	if err != nil {
+		utils.Log.Fatalf("Failed to init wallet service: %v", err)
+	}
+	fromAccount, err := svc.GetAccount(sender.Hex())
+	if err != nil {
+		utils.Log.Fatalf("Cannot replace %s: %v", txHash, err)
+	}
+
+	replacement, err := client.BuildReplacement(pending, sender, cancel)
+	if err != nil {
+		utils.Log.Fatalf("Failed to build replacement: %v", err)
+	}
+
+	if cancel {
+		fmt.Printf("\n⚠️  CONFIRM CANCEL\n")
+	} else {
+		fmt.Printf("\n⚠️  CONFIRM SPEED-UP\n")
+	}
+	fmt.Printf("Replacing:     %s\n", txHash)
+	if pending.Type() == types.DynamicFeeTxType {
+		fmt.Printf("Old Max Fee:   %s gwei\n", gwei(pending.GasFeeCap()))
+		fmt.Printf("Old Priority:  %s gwei\n", gwei(pending.GasTipCap()))
+	} else {
+		fmt.Printf("Old Gas Price: %s gwei\n", gwei(pending.GasPrice()))
+	}
+	printTxDetails(replacement, client.ChainID, sender, client.Config.Symbol, nil)
+
+	password := readSecret("Enter password to confirm: ")
+	newHash, err := client.SendReplacement(fromAccount, replacement, passwordSigner(svc, password))
+	if err != nil {
+		utils.Log.Fatalf("Failed to send replacement: %v", err)
+	}
+	printTxSent(client, newHash)
+}
+
+// printTxDetails prints what a transaction does and what it may cost; info is the token
+// for token transfers and may be nil
+func printTxDetails(tx *types.Transaction, chainID *big.Int, from common.Address, symbol string, info *chain.TokenInfo) {
+	fmt.Printf("Chain ID:      %s\n", chainID)
+	fmt.Printf("From:          %s\n", from.Hex())
+	if recipient, amount, ok := chain.DecodeTokenTransfer(tx); ok && info != nil {
+		fmt.Printf("To:            %s\n", recipient.Hex())
+		fmt.Printf("Amount:        %s %s (raw %s)\n", units.FormatUnits(amount, int(info.Decimals)), tokenSymbol(info), amount)
+		fmt.Printf("Token:         %s\n", tx.To().Hex())
+	} else {
+		if tx.To() == nil {
+			fmt.Printf("To:            (contract creation)\n")
+		} else {
+			fmt.Printf("To:            %s\n", tx.To().Hex())
+		}
+		fmt.Printf("Amount:        %s %s\n", units.FormatUnits(tx.Value(), units.EtherDecimals), symbol)
+		if len(tx.Data()) > 0 {
+			fmt.Printf("Data:          %d bytes\n", len(tx.Data()))
+		}
+	}
+	fmt.Printf("Nonce:         %d\n", tx.Nonce())
+	fmt.Printf("Gas Limit:     %d\n", tx.Gas())
+	if tx.Type() == types.DynamicFeeTxType {
+		fmt.Printf("Max Fee:       %s gwei\n", gwei(tx.GasFeeCap()))
+		fmt.Printf("Priority Fee:  %s gwei\n", gwei(tx.GasTipCap()))
+	} else {
+		fmt.Printf("Gas Price:     %s gwei\n", gwei(tx.GasPrice()))
+	}
+	if len(tx.AccessList()) > 0 {
+		fmt.Printf("Access List:   %d addresses, %d storage keys\n", len(tx.AccessList()), tx.AccessList().StorageKeys())
+	}
+	maxCost := new(big.Int).Mul(tx.GasFeeCap(), new(big.Int).SetUint64(tx.Gas()))
+	fmt.Printf("Max Gas Cost:  %s %s\n", units.FormatUnits(maxCost, units.EtherDecimals), symbol)
+	fmt.Println(strings.Repeat("-", 40))
+}
+
+func init() {
+	rootCmd.AddCommand(txCmd)
+	txCmd.AddCommand(txBuildCmd)
+	txCmd.AddCommand(txSignCmd)
+	txCmd.AddCommand(txBroadcastCmd)
+	txCmd.AddCommand(txSpeedupCmd)
+	txCmd.AddCommand(txCancelCmd)
+	txCmd.AddCommand(txNonceCmd)
+
+	txBuildCmd.Flags().StringVarP(&txBuildToken, "token", "t", "", "ERC20 token address")
+	txBuildCmd.Flags().StringVarP(&txBuildOutput, "output", "o", "unsigned-tx.json", "new file to write the unsigned transaction to")
+	txSignCmd.Flags().StringVarP(&txSignOutput, "output", "o", "", "write the raw signed transaction to this new file instead of stdout")
+	addFeeFlags(txBuildCmd, txSpeedupCmd, txCancelCmd)
+	addGasLimitFlag(txBuildCmd)
+	txNonceCmd.Flags().BoolVar(&txNonceReset, "reset", false, "forget the tracked nonces and continue from the node's pending nonce")
+}
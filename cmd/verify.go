@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"tokit/internal/utils"
+	"tokit/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+var verifyAddress string
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Recover the signer of off-chain signatures",
+}
+
+var verifyMessageCmd = &cobra.Command{
+	Use:   "message <signature> [\"<text>\"]",
+	Short: "Recover the signer of an EIP-191 personal message",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		sig := decodeSignature(args[0])
+		msg := readMessage(args[1:])
+
+		signer, err := wallet.RecoverMessage(msg, sig)
+		if err != nil {
+			utils.Log.Fatalf("Failed to recover signer: %v", err)
+		}
+		reportSigner(signer)
+	},
+}
+
+var verifyTypedDataCmd = &cobra.Command{
+	Use:   "typed-data <file.json> <signature>",
+	Short: "Recover the signer of EIP-712 typed data",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		typedData := readTypedData(args[0])
+		sig := decodeSignature(args[1])
+
+		signer, err := wallet.RecoverTypedData(typedData, sig)
+		if err != nil {
+			utils.Log.Fatalf("Failed to recover signer: %v", err)
+		}
+		reportSigner(signer)
+	},
+}
+
+func decodeSignature(s string) []byte {
+	sig, err := hexutil.Decode(s)
+	if err != nil {
+		utils.Log.Fatalf("Invalid signature: %v", err)
+	}
+	return sig
+}
+
+// reportSigner prints the recovered signer and, with --address, fails unless it matches
+func reportSigner(signer common.Address) {
+	fmt.Printf("Signer: %s\n", signer.Hex())
+	if verifyAddress == "" {
+		return
+	}
+
+	expected := common.HexToAddress(resolveAddress(verifyAddress))
+	if signer != expected {
+		utils.Log.Fatalf("❌ Signature does NOT match %s", expected.Hex())
+	}
+	fmt.Printf("✅ Signature matches %s\n", expected.Hex())
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.AddCommand(verifyMessageCmd)
+	verifyCmd.AddCommand(verifyTypedDataCmd)
+
+	verifyCmd.PersistentFlags().StringVarP(&verifyAddress, "address", "a", "", "expected signer: address, label or list index")
+	verifyMessageCmd.Flags().StringVar(&messageFile, "file", "", "read the message from this file")
+	verifyMessageCmd.Flags().StringVar(&messageHex, "hex", "", "message as 0x-prefixed hex bytes")
+}
@@ -1,17 +1,25 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
-	"syscall"
 	"text/tabwriter"
 	"tokit/internal/utils"
 	"tokit/internal/wallet"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
+)
+
+var (
+	walletPath       string
+	walletPassphrase bool
+	walletSaveSeed   bool
 )
 
 var walletCmd = &cobra.Command{
@@ -19,11 +27,16 @@ var walletCmd = &cobra.Command{
 	Short: "Manage wallet accounts",
 }
 
+var (
+	createWords    int
+	createLanguage string
+)
+
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new wallet with a random mnemonic",
 	Run: func(cmd *cobra.Command, args []string) {
-		mnemonic, err := wallet.GenerateMnemonic()
+		mnemonic, err := wallet.GenerateMnemonic(createWords, createLanguage)
 		if err != nil {
 			utils.Log.Fatalf("Failed to generate mnemonic: %v", err)
 		}
@@ -33,36 +46,37 @@ var createCmd = &cobra.Command{
 		fmt.Println(mnemonic)
 		fmt.Println(strings.Repeat("=", 60))
 
-		fmt.Print("\nEnter a password to encrypt your wallet: ")
-		bytePassword, err := term.ReadPassword(int(syscall.Stdin))
-		if err != nil {
-			utils.Log.Fatalf("Failed to read password: %v", err)
+		passphrase := ""
+		if walletPassphrase {
+			fmt.Println()
+			passphrase = readPassphrase(true)
 		}
-		password := string(bytePassword)
-		fmt.Println()
 
-		fmt.Print("Confirm password: ")
-		byteConfirm, err := term.ReadPassword(int(syscall.Stdin))
-		if err != nil {
-			utils.Log.Fatalf("Failed to read password: %v", err)
-		}
-		if password != string(byteConfirm) {
+		password := readSecret("\nEnter a password to encrypt your wallet: ")
+		if password != readSecret("Confirm password: ") {
 			utils.Log.Fatal("Passwords do not match")
 		}
-		fmt.Println()
 
 		svc, err := wallet.NewService()
 		if err != nil {
 			utils.Log.Fatalf("Failed to init wallet service: %v", err)
 		}
 
-		acc, err := svc.ImportMnemonic(mnemonic, password)
+		hd, err := wallet.NewHDWallet(mnemonic, passphrase)
+		if err != nil {
+			utils.Log.Fatalf("Failed to load mnemonic: %v", err)
+		}
+
+		acc, err := svc.ImportHDAccount(hd, walletPath, 0, password)
 		if err != nil {
 			utils.Log.Fatalf("Failed to create account: %v", err)
 		}
 
 		fmt.Printf("\n✅ Wallet created successfully!\nAddress: %s\n", acc.Address.Hex())
 		fmt.Printf("Keystore location: %s\n", acc.URL.Path)
+		if walletSaveSeed {
+			saveSeed(svc, hd, mnemonic, passphrase, password)
+		}
 	},
 }
 
@@ -81,47 +95,360 @@ var listCmd = &cobra.Command{
 			return
 		}
 
+		// Group HD accounts by seed, ordered by derivation index; standalone keys go last
+		order := make([]int, len(accounts))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			ma := svc.AccountMeta(accounts[order[a]].Address)
+			mb := svc.AccountMeta(accounts[order[b]].Address)
+			if ma.Seed != mb.Seed {
+				if ma.Seed == "" || mb.Seed == "" {
+					return mb.Seed == ""
+				}
+				return ma.Seed < mb.Seed
+			}
+			return ma.Index < mb.Index
+		})
+
 		w := new(tabwriter.Writer)
 		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
-		fmt.Fprintln(w, "Index\tAddress\tLocation")
+		fmt.Fprintln(w, "Index\tLabel\tAddress\tSource\tSeed\tPath\tTags\tLocation")
 
-		for i, acc := range accounts {
-			fmt.Fprintf(w, "%d\t%s\t%s\n", i, acc.Address.Hex(), acc.URL.Path)
+		for _, i := range order {
+			acc := accounts[i]
+			meta := svc.AccountMeta(acc.Address)
+			seed, path := "-", "-"
+			if meta.Seed != "" {
+				seed, path = meta.Seed, meta.Path
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", i, orDash(meta.Label), acc.Address.Hex(),
+				orDash(meta.Source), seed, path, orDash(strings.Join(meta.Tags, ",")), acc.URL.Path)
 		}
 		w.Flush()
 	},
 }
 
-var importCmd = &cobra.Command{
-	Use:   "import",
-	Short: "Import a wallet using mnemonic or private key",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Print("Enter mnemonic phrase or private key (hex): ")
+var labelTags []string
 
-		byteInput, err := term.ReadPassword(int(syscall.Stdin))
+var labelCmd = &cobra.Command{
+	Use:   "label <account> <name>",
+	Short: "Set the label and tags of an account",
+	Long: `Set a label for an account. The account can be given as an address, its current label or its list index.
+Labels can be used anywhere an address is accepted. Pass an empty name ("") to remove the label.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, err := wallet.NewService()
 		if err != nil {
-			utils.Log.Fatalf("Failed to read input: %v", err)
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
 		}
-		input := strings.TrimSpace(string(byteInput))
-		fmt.Println("\n(Input received)")
 
-		fmt.Print("Enter a password to encrypt your wallet: ")
-		bytePassword, err := term.ReadPassword(int(syscall.Stdin))
+		acc, err := svc.GetAccount(args[0])
 		if err != nil {
-			utils.Log.Fatalf("Failed to read password: %v", err)
+			utils.Log.Fatalf("Failed to find account: %v", err)
+		}
+
+		if err := svc.SetLabel(acc.Address, args[1]); err != nil {
+			utils.Log.Fatalf("Failed to set label: %v", err)
+		}
+		if cmd.Flags().Changed("tag") {
+			if err := svc.SetTags(acc.Address, labelTags); err != nil {
+				utils.Log.Fatalf("Failed to set tags: %v", err)
+			}
+		}
+
+		meta := svc.AccountMeta(acc.Address)
+		fmt.Printf("✅ %s labelled %q", acc.Address.Hex(), meta.Label)
+		if len(meta.Tags) > 0 {
+			fmt.Printf(" [%s]", strings.Join(meta.Tags, ", "))
 		}
-		password := string(bytePassword)
 		fmt.Println()
+	},
+}
+
+var (
+	exportFormat string
+	exportOutput string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <account>",
+	Short: "Export an account as keystore JSON or raw private key",
+	Long: `Export an account's key. The account can be given as an address, label or list index.
+--format keystore writes the encrypted V3 keystore JSON; --format hex prints the unencrypted private key.
+With --output the key is written to a new file with 0600 permissions instead of stdout.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if exportFormat != "keystore" && exportFormat != "hex" {
+			utils.Log.Fatalf("Invalid format %q: must be keystore or hex", exportFormat)
+		}
 
 		svc, err := wallet.NewService()
 		if err != nil {
 			utils.Log.Fatalf("Failed to init wallet service: %v", err)
 		}
+		acc, err := svc.GetAccount(args[0])
+		if err != nil {
+			utils.Log.Fatalf("Failed to find account: %v", err)
+		}
+
+		if exportFormat == "hex" {
+			fmt.Println("⚠️  WARNING: YOU ARE ABOUT TO EXPORT AN UNENCRYPTED PRIVATE KEY!")
+			fmt.Println(strings.Repeat("=", 60))
+			fmt.Println("Anyone who sees this key can steal ALL funds of this account.")
+			fmt.Println("Never paste it into a website, chat or email, and never share it with support staff.")
+			fmt.Println(strings.Repeat("=", 60))
+			fmt.Printf("Account: %s\n\n", acc.Address.Hex())
+			if readLine(`Type "EXPORT" to continue: `) != "EXPORT" {
+				utils.Log.Fatal("Aborted")
+			}
+		}
+
+		password := readSecret("Enter password: ")
+
+		var output []byte
+		if exportFormat == "hex" {
+			key, err := svc.ExportPrivateKey(acc, password)
+			if err != nil {
+				utils.Log.Fatalf("Failed to export private key: %v", err)
+			}
+			output = []byte(key + "\n")
+		} else {
+			output, err = svc.ExportKeystore(acc, password)
+			if err != nil {
+				utils.Log.Fatalf("Failed to export keystore: %v", err)
+			}
+			output = append(output, '\n')
+		}
+
+		if exportOutput == "" {
+			fmt.Printf("\n%s", output)
+			return
+		}
+
+		// Never overwrite an existing file
+		f, err := os.OpenFile(exportOutput, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			utils.Log.Fatalf("Failed to create output file: %v", err)
+		}
+		if _, err := f.Write(output); err != nil {
+			f.Close()
+			utils.Log.Fatalf("Failed to write output file: %v", err)
+		}
+		if err := f.Close(); err != nil {
+			utils.Log.Fatalf("Failed to write output file: %v", err)
+		}
+		fmt.Printf("\n✅ Exported %s to %s\n", acc.Address.Hex(), exportOutput)
+	},
+}
+
+var passwdCmd = &cobra.Command{
+	Use:   "passwd <account>",
+	Short: "Change the password of an account",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+		acc, err := svc.GetAccount(args[0])
+		if err != nil {
+			utils.Log.Fatalf("Failed to find account: %v", err)
+		}
+
+		fmt.Printf("Changing password for %s\n", acc.Address.Hex())
+		password := readSecret("Enter current password: ")
+		newPassword := readSecret("Enter new password: ")
+		if newPassword != readSecret("Confirm new password: ") {
+			utils.Log.Fatal("Passwords do not match")
+		}
+
+		if err := svc.UpdatePassword(acc, password, newPassword); err != nil {
+			utils.Log.Fatalf("Failed to change password: %v", err)
+		}
+		fmt.Println("\n✅ Password changed successfully!")
+	},
+}
+
+var removeCmd = &cobra.Command{
+	Use:   "remove <account>",
+	Short: "Remove an account from the keystore",
+	Long: `Remove an account from the keystore. The account's password is required, and the
+encrypted key file is copied to ~/.tokit/backup before it is deleted.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+		acc, err := svc.GetAccount(args[0])
+		if err != nil {
+			utils.Log.Fatalf("Failed to find account: %v", err)
+		}
+
+		fmt.Printf("⚠️  You are about to remove %s from the keystore.\n", acc.Address.Hex())
+		if meta := svc.AccountMeta(acc.Address); meta.Label != "" {
+			fmt.Printf("Label: %s\n", meta.Label)
+		}
+		if !confirm("Remove this account?") {
+			utils.Log.Fatal("Aborted")
+		}
+
+		password := readSecret("Enter password: ")
+		backupPath, err := svc.DeleteAccount(acc, password)
+		if err != nil {
+			utils.Log.Fatalf("Failed to remove account: %v", err)
+		}
+
+		fmt.Printf("\n✅ Account removed.\nBackup: %s\n", backupPath)
+	},
+}
+
+var (
+	deriveIndex int
+	deriveCount int
+	deriveSeed  string
+)
+
+var deriveCmd = &cobra.Command{
+	Use:   "derive",
+	Short: "Derive additional accounts from a mnemonic",
+	Long: `Derive more accounts from the same mnemonic and import them into the keystore.
+With --seed, the mnemonic is read from the encrypted seed vault instead of being typed in.
+Accounts are derived along --path, which must be a preset or a template containing N.
+Without --index, derivation continues after the highest index already derived from that seed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if deriveCount < 1 {
+			utils.Log.Fatal("Count must be at least 1")
+		}
+		if !wallet.IsIndexedPath(walletPath) {
+			utils.Log.Fatal("Derivation path must be a preset or contain N as the account index")
+		}
+
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+
+		var (
+			hd                   *wallet.HDWallet
+			mnemonic, passphrase string
+			password             string
+		)
+		if deriveSeed != "" {
+			password = readSecret("Enter password: ")
+			hd, err = svc.OpenSeed(deriveSeed, password)
+			if err != nil {
+				utils.Log.Fatalf("Failed to open seed: %v", err)
+			}
+		} else {
+			mnemonic = strings.TrimSpace(readSecret("Enter mnemonic phrase: "))
+			if walletPassphrase {
+				passphrase = readPassphrase(false)
+			}
+			hd, err = wallet.NewHDWallet(mnemonic, passphrase)
+			if err != nil {
+				utils.Log.Fatalf("Failed to load mnemonic: %v", err)
+			}
+		}
+
+		start := svc.NextIndex(hd, walletPath)
+		if cmd.Flags().Changed("index") {
+			if deriveIndex < 0 {
+				utils.Log.Fatal("Index must not be negative")
+			}
+			start = uint32(deriveIndex)
+		}
+
+		if walletPassphrase && !confirmHDWallet(hd, start) {
+			utils.Log.Fatal("Aborted")
+		}
+
+		if password == "" {
+			password = readSecret("Enter a password to encrypt the derived accounts: ")
+		}
+
+		fmt.Printf("\nSeed %s\n", hd.ID)
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "Path\tAddress\tStatus")
+
+		for i := start; i < start+uint32(deriveCount); i++ {
+			path, err := wallet.ResolvePath(walletPath, i)
+			if err != nil {
+				utils.Log.Fatalf("Failed to resolve path: %v", err)
+			}
+			addr, err := hd.Address(path)
+			if err != nil {
+				utils.Log.Fatalf("Failed to derive %s: %v", path, err)
+			}
+			if svc.HasAccount(addr) {
+				fmt.Fprintf(w, "%s\t%s\talready in keystore\n", path, addr.Hex())
+				continue
+			}
+
+			acc, err := svc.ImportHDAccount(hd, walletPath, i, password)
+			if err != nil {
+				utils.Log.Fatalf("Failed to import %s: %v", path, err)
+			}
+			fmt.Fprintf(w, "%s\t%s\timported\n", path, acc.Address.Hex())
+		}
+		w.Flush()
+
+		if deriveSeed == "" && walletSaveSeed {
+			saveSeed(svc, hd, mnemonic, passphrase, password)
+		}
+	},
+}
+
+var importKeystore string
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a wallet using mnemonic, private key or keystore JSON",
+	Long: `Import a wallet using a mnemonic phrase or private key (hex), entered interactively.
+With --keystore, imports a V3 keystore JSON file (geth, MetaMask, MyEtherWallet, ...) and re-encrypts
+it under a new password. If --keystore points to a directory, every V3 keystore file in it is imported.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if importKeystore != "" {
+			importKeystoreFiles(importKeystore)
+			return
+		}
+
+		input := strings.TrimSpace(readSecret("Enter mnemonic phrase or private key (hex): "))
+		fmt.Println("(Input received)")
 
-		var acc accounts.Account
 		// Check if input is mnemonic (has spaces) or private key (hex)
+		var (
+			hd         *wallet.HDWallet
+			passphrase string
+		)
 		if strings.Contains(input, " ") {
-			acc, err = svc.ImportMnemonic(input, password)
+			if walletPassphrase {
+				passphrase = readPassphrase(false)
+			}
+
+			var err error
+			hd, err = wallet.NewHDWallet(input, passphrase)
+			if err != nil {
+				utils.Log.Fatalf("Failed to load mnemonic: %v", err)
+			}
+			if !confirmHDWallet(hd, 0) {
+				utils.Log.Fatal("Aborted")
+			}
+		}
+
+		password := readSecret("Enter a password to encrypt your wallet: ")
+
+		svc, err := wallet.NewService()
+		if err != nil {
+			utils.Log.Fatalf("Failed to init wallet service: %v", err)
+		}
+
+		var acc accounts.Account
+		if hd != nil {
+			acc, err = svc.ImportHDAccount(hd, walletPath, 0, password)
 		} else {
 			// Assume private key
 			input = strings.TrimPrefix(input, "0x")
@@ -134,12 +461,155 @@ var importCmd = &cobra.Command{
 
 		fmt.Printf("\n✅ Wallet imported successfully!\nAddress: %s\n", acc.Address.Hex())
 		fmt.Printf("Keystore location: %s\n", acc.URL.Path)
+		if hd != nil && walletSaveSeed {
+			saveSeed(svc, hd, input, passphrase, password)
+		}
 	},
 }
 
+// importKeystoreFiles imports a single keystore file or every V3 keystore file in a directory
+func importKeystoreFiles(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		utils.Log.Fatalf("Failed to read keystore path: %v", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			utils.Log.Fatalf("Failed to read directory: %v", err)
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	keyFiles := make(map[string][]byte)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			utils.Log.Fatalf("Failed to read %s: %v", file, err)
+		}
+		if wallet.IsKeyJSON(data) {
+			keyFiles[file] = data
+		} else if !info.IsDir() {
+			utils.Log.Fatalf("%s is not a V3 keystore file", file)
+		}
+	}
+	if len(keyFiles) == 0 {
+		utils.Log.Fatalf("No V3 keystore files found in %s", path)
+	}
+	fmt.Printf("Found %d keystore file(s)\n", len(keyFiles))
+
+	oldPassword := readSecret("Enter the current keystore password: ")
+	password := readSecret("Enter a new password to encrypt your wallet: ")
+	if password != readSecret("Confirm password: ") {
+		utils.Log.Fatal("Passwords do not match")
+	}
+
+	svc, err := wallet.NewService()
+	if err != nil {
+		utils.Log.Fatalf("Failed to init wallet service: %v", err)
+	}
+
+	fmt.Println()
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+	fmt.Fprintln(w, "File\tAddress\tStatus")
+
+	failed := 0
+	for _, file := range files {
+		data, ok := keyFiles[file]
+		if !ok {
+			continue
+		}
+		acc, err := svc.ImportAccount(data, oldPassword, password)
+		switch {
+		case errors.Is(err, keystore.ErrAccountAlreadyExists):
+			fmt.Fprintf(w, "%s\t%s\talready in keystore\n", filepath.Base(file), acc.Address.Hex())
+		case err != nil:
+			failed++
+			fmt.Fprintf(w, "%s\t%s\tfailed: %v\n", filepath.Base(file), "-", err)
+		default:
+			fmt.Fprintf(w, "%s\t%s\timported\n", filepath.Base(file), acc.Address.Hex())
+		}
+	}
+	w.Flush()
+
+	if failed > 0 {
+		utils.Log.Fatalf("%d keystore file(s) could not be imported", failed)
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// saveSeed stores the mnemonic in the seed vault, encrypted with the wallet password
+func saveSeed(svc *wallet.Service, hd *wallet.HDWallet, mnemonic, passphrase, password string) {
+	saved, err := svc.SaveSeed(hd, mnemonic, passphrase, password)
+	if err != nil {
+		utils.Log.Warnf("Failed to store seed in vault: %v", err)
+		return
+	}
+	if saved {
+		fmt.Printf("Seed %s stored in the encrypted seed vault (same password as the wallet)\n", hd.ID)
+	}
+}
+
+// confirmHDWallet shows the seed fingerprint and the address at index so the user can
+// check that the mnemonic and passphrase were entered correctly
+func confirmHDWallet(hd *wallet.HDWallet, index uint32) bool {
+	path, err := wallet.ResolvePath(walletPath, index)
+	if err != nil {
+		utils.Log.Fatalf("Failed to resolve path: %v", err)
+	}
+	addr, err := hd.Address(path)
+	if err != nil {
+		utils.Log.Fatalf("Failed to derive %s: %v", path, err)
+	}
+
+	fmt.Printf("\nSeed fingerprint: %s (%s)\n", hd.ID, hd.Language)
+	fmt.Printf("Address (%s): %s\n", path, addr.Hex())
+	return confirm("Is this the address you expect?")
+}
+
 func init() {
 	rootCmd.AddCommand(walletCmd)
 	walletCmd.AddCommand(createCmd)
 	walletCmd.AddCommand(listCmd)
 	walletCmd.AddCommand(importCmd)
+	walletCmd.AddCommand(deriveCmd)
+	walletCmd.AddCommand(labelCmd)
+	walletCmd.AddCommand(exportCmd)
+	walletCmd.AddCommand(passwdCmd)
+	walletCmd.AddCommand(removeCmd)
+
+	importCmd.Flags().StringVar(&importKeystore, "keystore", "", "import a V3 keystore JSON file, or every keystore file in a directory")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "keystore", "export format: keystore or hex")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "write to this file (0600) instead of stdout")
+	labelCmd.Flags().StringSliceVar(&labelTags, "tag", nil, "tags for the account (repeatable or comma-separated, replaces existing tags)")
+
+	pathUsage := fmt.Sprintf("derivation path: a preset (%s), a template with N as the index, or a BIP32 path",
+		strings.Join(wallet.PresetNames(), ", "))
+	for _, c := range []*cobra.Command{createCmd, importCmd, deriveCmd} {
+		c.Flags().StringVar(&walletPath, "path", wallet.DefaultPathPreset, pathUsage)
+		c.Flags().BoolVar(&walletPassphrase, "passphrase", false, "prompt for a BIP39 passphrase (\"25th word\")")
+		c.Flags().BoolVar(&walletSaveSeed, "save-seed", false, "store the mnemonic in the encrypted seed vault")
+	}
+
+	createCmd.Flags().IntVar(&createWords, "words", 12, "number of mnemonic words: 12, 15, 18, 21 or 24")
+	createCmd.Flags().StringVar(&createLanguage, "language", wallet.DefaultLanguage,
+		fmt.Sprintf("mnemonic wordlist language (%s)", strings.Join(wallet.Languages(), ", ")))
+
+	deriveCmd.Flags().IntVar(&deriveIndex, "index", 0, "derivation index to start from (default: next unused index)")
+	deriveCmd.Flags().IntVar(&deriveCount, "count", 1, "number of accounts to derive")
+	deriveCmd.Flags().StringVar(&deriveSeed, "seed", "", "fingerprint of a seed in the vault to derive from")
 }
@@ -0,0 +1,87 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// approvalTopic is the event signature of the ERC20 Approval event
+var approvalTopic = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+
+// ApprovalPair is a token/spender combination the owner has approved at some point
+type ApprovalPair struct {
+	Token   common.Address
+	Spender common.Address
+	// Block is the most recent block with an Approval event for the pair
+	Block uint64
+}
+
+// FindApprovals scans Approval events emitted for owner between fromBlock and toBlock,
+// querying at most chunkSize blocks per request. The chunk is halved when the node rejects
+// a range. progress, if set, is called after each chunk.
+func (c *Client) FindApprovals(
+	owner common.Address,
+	fromBlock, toBlock, chunkSize uint64,
+	progress func(scannedTo uint64),
+) ([]ApprovalPair, error) {
+	if chunkSize == 0 {
+		return nil, fmt.Errorf("chunk size must be greater than 0")
+	}
+
+	ctx := context.Background()
+	ownerTopic := common.BytesToHash(owner.Bytes())
+	pairs := make(map[[2]common.Address]uint64)
+
+	for start := fromBlock; start <= toBlock; {
+		end := start + chunkSize - 1
+		if end > toBlock || end < start {
+			end = toBlock
+		}
+
+		logs, err := c.EthClient.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Topics:    [][]common.Hash{{approvalTopic}, {ownerTopic}},
+		})
+		if err != nil {
+			if chunkSize > 1 {
+				// Most providers cap the block range or result size of eth_getLogs
+				chunkSize /= 2
+				continue
+			}
+			return nil, fmt.Errorf("failed to get logs for blocks %d-%d: %w", start, end, err)
+		}
+
+		for _, log := range logs {
+			// ERC721 Approval events index the token ID as a fourth topic
+			if len(log.Topics) != 3 || log.Removed {
+				continue
+			}
+			key := [2]common.Address{log.Address, common.BytesToAddress(log.Topics[2].Bytes())}
+			if log.BlockNumber >= pairs[key] {
+				pairs[key] = log.BlockNumber
+			}
+		}
+
+		if progress != nil {
+			progress(end)
+		}
+		if end == toBlock {
+			break
+		}
+		start = end + 1
+	}
+
+	result := make([]ApprovalPair, 0, len(pairs))
+	for key, block := range pairs {
+		result = append(result, ApprovalPair{Token: key[0], Spender: key[1], Block: block})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Block > result[j].Block })
+	return result, nil
+}
@@ -15,6 +15,11 @@ type Client struct {
 	EthClient *ethclient.Client
 	ChainID   *big.Int
 	Config    config.NetworkConfig
+	Fees      FeeOptions
+	GasLimit  uint64 // overrides gas estimates when set; only for single-transaction sends
+
+	tokens *tokenCache
+	txType string
 }
 
 // NewClient creates a new client for the specified chain
@@ -54,6 +59,11 @@ func (c *Client) GetBalance(address string) (*big.Int, error) {
 	return c.EthClient.BalanceAt(context.Background(), account, nil)
 }
 
+// BlockNumber returns the number of the latest block
+func (c *Client) BlockNumber() (uint64, error) {
+	return c.EthClient.BlockNumber(context.Background())
+}
+
 func (c *Client) Close() {
 	c.EthClient.Close()
 }
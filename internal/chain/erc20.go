@@ -1,9 +1,16 @@
 package chain
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math/big"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"tokit/internal/utils"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
@@ -12,25 +19,110 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+const (
+	// resetWaitTimeout is how long ApproveWithReset waits for the reset to be mined
+	resetWaitTimeout = 15 * time.Minute
+
+	// maxTokenStringLen caps the length in characters of a token symbol or name
+	maxTokenStringLen = 64
+)
+
 // ERC20 ABI Method IDs
 var (
 	transferMethodID  = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
 	balanceOfMethodID = crypto.Keccak256([]byte("balanceOf(address)"))[:4]
+	allowanceMethodID = crypto.Keccak256([]byte("allowance(address,address)"))[:4]
+	approveMethodID   = crypto.Keccak256([]byte("approve(address,uint256)"))[:4]
+	decimalsMethodID  = crypto.Keccak256([]byte("decimals()"))[:4]
+	symbolMethodID    = crypto.Keccak256([]byte("symbol()"))[:4]
+	nameMethodID      = crypto.Keccak256([]byte("name()"))[:4]
 )
 
-// GetTokenBalance returns the balance of an ERC20 token
-func (c *Client) GetTokenBalance(tokenAddress, ownerAddress string) (*big.Int, error) {
+// TokenInfo holds the ERC20 metadata of a token contract
+type TokenInfo struct {
+	Address  common.Address `json:"address"`
+	Name     string         `json:"name"`
+	Symbol   string         `json:"symbol"`
+	Decimals uint8          `json:"decimals"`
+}
+
+// GetTokenInfo returns the decimals, symbol and name of an ERC20 token, cached per chain
+func (c *Client) GetTokenInfo(tokenAddress string) (*TokenInfo, error) {
+	if !common.IsHexAddress(tokenAddress) {
+		return nil, fmt.Errorf("invalid token address: %s", tokenAddress)
+	}
 	tokenAddr := common.HexToAddress(tokenAddress)
-	ownerAddr := common.HexToAddress(ownerAddress)
 
-	// Construct data: balanceOf(address)
-	// methodID (4 bytes) + padded address (32 bytes)
-	data := make([]byte, 0)
-	data = append(data, balanceOfMethodID...)
-	data = append(data, common.LeftPadBytes(ownerAddr.Bytes(), 32)...)
+	// The cache is only an optimization; failing to read or write it is not fatal
+	if c.tokens == nil {
+		cache, err := loadTokenCache(c.ChainID.Int64())
+		if err != nil {
+			utils.Log.Warnf("Failed to load token cache: %v", err)
+		}
+		c.tokens = cache
+	}
+	if info, ok := c.tokens.get(tokenAddr); ok {
+		return info, nil
+	}
+
+	// decimals() is required to scale amounts; symbol() and name() are optional in ERC20
+	result, err := c.callContract(tokenAddr, decimalsMethodID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decimals: %w", err)
+	}
+	decimals := new(big.Int).SetBytes(result)
+	if len(result) != 32 || !decimals.IsUint64() || decimals.Uint64() > 255 {
+		return nil, fmt.Errorf("invalid decimals() result from %s", tokenAddr.Hex())
+	}
+
+	info := &TokenInfo{
+		Address:  tokenAddr,
+		Decimals: uint8(decimals.Uint64()),
+	}
+	if result, err := c.callContract(tokenAddr, symbolMethodID); err == nil {
+		info.Symbol, _ = decodeString(result)
+	}
+	if result, err := c.callContract(tokenAddr, nameMethodID); err == nil {
+		info.Name, _ = decodeString(result)
+	}
+	info.sanitize()
+
+	// Failed or empty symbol() and name() calls may be transient; look them up again next time
+	if info.Symbol == "" || info.Name == "" {
+		return info, nil
+	}
+	if err := c.tokens.set(info); err != nil {
+		utils.Log.Warnf("Failed to save token cache: %v", err)
+	}
+	return info, nil
+}
+
+// sanitize cleans the symbol and name, which the token contract chooses freely, for
+// display: control characters could rewrite the terminal and spoof a confirmation prompt
+func (t *TokenInfo) sanitize() {
+	t.Symbol = sanitizeTokenString(t.Symbol)
+	t.Name = sanitizeTokenString(t.Name)
+}
 
+// sanitizeTokenString drops invalid UTF-8 and non-printable characters and caps the length
+func sanitizeTokenString(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.TrimSpace(s)
+	if runes := []rune(s); len(runes) > maxTokenStringLen {
+		s = string(runes[:maxTokenStringLen])
+	}
+	return s
+}
+
+// callContract performs an eth_call against the latest block
+func (c *Client) callContract(to common.Address, data []byte) ([]byte, error) {
 	msg := ethereum.CallMsg{
-		To:   &tokenAddr,
+		To:   &to,
 		Data: data,
 	}
 
@@ -38,92 +130,239 @@ func (c *Client) GetTokenBalance(tokenAddress, ownerAddress string) (*big.Int, e
 	if err != nil {
 		return nil, fmt.Errorf("failed to call contract: %w", err)
 	}
-
 	if len(result) == 0 {
 		return nil, fmt.Errorf("no result from contract call")
 	}
+	return result, nil
+}
+
+// decodeString decodes an ABI-encoded string return value. Some older tokens (MKR, SAI, ...)
+// return bytes32 instead, which is detected by the 32-byte length and zero-trimmed.
+func decodeString(result []byte) (string, error) {
+	if len(result) == 32 {
+		return string(bytes.TrimRight(result, "\x00")), nil
+	}
+	if len(result) < 64 {
+		return "", fmt.Errorf("invalid string result of %d bytes", len(result))
+	}
+
+	offset := new(big.Int).SetBytes(result[:32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(result)-32) {
+		return "", fmt.Errorf("invalid string offset")
+	}
+	start := offset.Uint64() + 32
+
+	length := new(big.Int).SetBytes(result[offset.Uint64():start])
+	if !length.IsUint64() || length.Uint64() > uint64(len(result))-start {
+		return "", fmt.Errorf("invalid string length")
+	}
+	return string(result[start : start+length.Uint64()]), nil
+}
+
+// GetTokenBalance returns the balance of an ERC20 token
+func (c *Client) GetTokenBalance(tokenAddress, ownerAddress string) (*big.Int, error) {
+	tokenAddr := common.HexToAddress(tokenAddress)
+	ownerAddr := common.HexToAddress(ownerAddress)
+
+	// Construct data: balanceOf(address)
+	// methodID (4 bytes) + padded address (32 bytes)
+	data := make([]byte, 0)
+	data = append(data, balanceOfMethodID...)
+	data = append(data, common.LeftPadBytes(ownerAddr.Bytes(), 32)...)
+
+	result, err := c.callContract(tokenAddr, data)
+	if err != nil {
+		return nil, err
+	}
 
 	return new(big.Int).SetBytes(result), nil
 }
 
 // SendTokenTransaction sends an ERC20 token transfer
+// amount is in the token's base units
 func (c *Client) SendTokenTransaction(
 	from accounts.Account,
 	tokenAddress string,
 	to string,
-	amount *big.Float,
-	signFn func(accounts.Account, *types.Transaction, *big.Int) (*types.Transaction, error),
+	amount *big.Int,
+	signFn SignerFn,
 ) (string, error) {
-	ctx := context.Background()
-	tokenAddr := common.HexToAddress(tokenAddress)
-	toAddr := common.HexToAddress(to)
+	data, err := transferData(common.HexToAddress(to), amount)
+	if err != nil {
+		return "", err
+	}
+	return c.sendTokenCall(from, common.HexToAddress(tokenAddress), data, signFn)
+}
+
+// BuildTokenTransaction builds an unsigned ERC20 token transfer
+func (c *Client) BuildTokenTransaction(from common.Address, tokenAddress, to string, amount *big.Int) (*types.Transaction, error) {
+	data, err := transferData(common.HexToAddress(to), amount)
+	if err != nil {
+		return nil, err
+	}
+	return c.buildTokenCall(nil, from, common.HexToAddress(tokenAddress), data)
+}
 
-	// 1. Convert amount to Wei (assuming 18 decimals for now, ideally should fetch decimals)
-	// TODO: Fetch decimals dynamically if needed, but standard is 18
-	weiValue := new(big.Int)
-	amount.Mul(amount, big.NewFloat(1e18)).Int(weiValue)
+// DecodeTokenTransfer returns the recipient and amount of a transaction calling
+// transfer(address,uint256), or false if it is not a token transfer
+func DecodeTokenTransfer(tx *types.Transaction) (common.Address, *big.Int, bool) {
+	data := tx.Data()
+	if tx.To() == nil || len(data) != 4+32+32 || !bytes.Equal(data[:4], transferMethodID) {
+		return common.Address{}, nil, false
+	}
+	return common.BytesToAddress(data[4:36]), new(big.Int).SetBytes(data[36:68]), true
+}
+
+func transferData(to common.Address, amount *big.Int) ([]byte, error) {
+	if err := checkUint256(amount); err != nil {
+		return nil, err
+	}
 
-	// 2. Construct Data: transfer(address,uint256)
+	// Construct Data: transfer(address,uint256)
 	data := make([]byte, 0)
 	data = append(data, transferMethodID...)
-	data = append(data, common.LeftPadBytes(toAddr.Bytes(), 32)...)
-	data = append(data, common.LeftPadBytes(weiValue.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(to.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+	return data, nil
+}
+
+// GetAllowance returns how much of the owner's tokens the spender may transfer
+func (c *Client) GetAllowance(tokenAddress, ownerAddress, spenderAddress string) (*big.Int, error) {
+	tokenAddr := common.HexToAddress(tokenAddress)
 
-	// 3. Get Nonce
-	nonce, err := c.EthClient.PendingNonceAt(ctx, from.Address)
+	// Construct data: allowance(address,address)
+	data := make([]byte, 0)
+	data = append(data, allowanceMethodID...)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(ownerAddress).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(spenderAddress).Bytes(), 32)...)
+
+	result, err := c.callContract(tokenAddr, data)
 	if err != nil {
-		return "", fmt.Errorf("failed to get nonce: %w", err)
+		return nil, err
 	}
 
-	// 4. Get Gas Tip Cap
-	gasTipCap, err := c.EthClient.SuggestGasTipCap(ctx)
+	return new(big.Int).SetBytes(result), nil
+}
+
+// Approve sets the spender's allowance over the sender's tokens; an amount of 0 revokes it
+func (c *Client) Approve(
+	from accounts.Account,
+	tokenAddress string,
+	spender string,
+	amount *big.Int,
+	signFn SignerFn,
+) (string, error) {
+	data, err := approveData(common.HexToAddress(spender), amount)
 	if err != nil {
-		return "", fmt.Errorf("failed to get gas tip cap: %w", err)
+		return "", err
 	}
+	return c.sendTokenCall(from, common.HexToAddress(tokenAddress), data, signFn)
+}
 
-	// 5. Get Header for Base Fee
-	head, err := c.EthClient.HeaderByNumber(ctx, nil)
+// ApproveWithReset sets the spender's allowance to zero, waits until that is mined and then
+// approves amount, for tokens that reject changing a non-zero allowance (see
+// ApproveNeedsReset). Until the reset is mined such tokens still revert the second approve,
+// so it can't be estimated or sent earlier. resetSent is called with the reset's hash and
+// may be nil.
+func (c *Client) ApproveWithReset(
+	from accounts.Account,
+	tokenAddress string,
+	spender string,
+	amount *big.Int,
+	signFn SignerFn,
+	resetSent func(txHash string),
+) (string, error) {
+	resetHash, err := c.Approve(from, tokenAddress, spender, big.NewInt(0), signFn)
 	if err != nil {
-		return "", fmt.Errorf("failed to get header: %w", err)
+		return "", fmt.Errorf("failed to send reset transaction: %w", err)
+	}
+	if resetSent != nil {
+		resetSent(resetHash)
 	}
 
-	// 6. Calculate Gas Fee Cap
-	gasFeeCap := new(big.Int).Add(
-		new(big.Int).Mul(head.BaseFee, big.NewInt(2)),
-		gasTipCap,
-	)
+	receipt, err := c.WaitForReceipt(resetHash, 1, resetWaitTimeout, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to wait for reset transaction: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return "", fmt.Errorf("reset transaction %s reverted", resetHash)
+	}
+	return c.Approve(from, tokenAddress, spender, amount, signFn)
+}
+
+// ApproveNeedsReset reports whether changing an existing non-zero allowance to amount is
+// rejected by the token. USDT and a few other tokens require the allowance to be set to
+// zero first; this is detected by simulating the approve call. Only a revert counts as
+// rejection; RPC failures are returned as errors.
+func (c *Client) ApproveNeedsReset(from common.Address, tokenAddress, spender string, amount *big.Int) (bool, error) {
+	if amount.Sign() == 0 {
+		return false, nil
+	}
 
-	// 7. Estimate Gas
-	gasLimit, err := c.EstimateGas(from.Address, tokenAddr, big.NewInt(0), data)
+	current, err := c.GetAllowance(tokenAddress, from.Hex(), spender)
 	if err != nil {
-		// Fallback if estimation fails (though it shouldn't for standard ERC20)
-		gasLimit = 100000 // Standard ERC20 transfer is usually ~65k
-	}
-
-	// 8. Create Transaction
-	// Note: 'To' is the Token Address, 'Value' is 0 (ETH), 'Data' contains the transfer details
-	tx := types.NewTx(&types.DynamicFeeTx{
-		ChainID:   c.ChainID,
-		Nonce:     nonce,
-		GasTipCap: gasTipCap,
-		GasFeeCap: gasFeeCap,
-		Gas:       gasLimit,
-		To:        &tokenAddr,
-		Value:     big.NewInt(0),
-		Data:      data,
-	})
+		return false, err
+	}
+	if current.Sign() == 0 {
+		return false, nil
+	}
 
-	// 9. Sign Transaction
-	signedTx, err := signFn(from, tx, c.ChainID)
+	data, err := approveData(common.HexToAddress(spender), amount)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %w", err)
+		return false, err
 	}
+	_, err = c.EstimateGas(from, common.HexToAddress(tokenAddress), big.NewInt(0), data)
+	if err != nil && !isRevert(err) {
+		return false, err
+	}
+	return err != nil, nil
+}
 
-	// 10. Send Transaction
-	err = c.EthClient.SendTransaction(ctx, signedTx)
+func approveData(spender common.Address, amount *big.Int) ([]byte, error) {
+	if err := checkUint256(amount); err != nil {
+		return nil, err
+	}
+
+	// Construct data: approve(address,uint256)
+	data := make([]byte, 0)
+	data = append(data, approveMethodID...)
+	data = append(data, common.LeftPadBytes(spender.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+	return data, nil
+}
+
+// checkUint256 rejects amounts that do not fit the 32-byte uint256 word of the calldata
+func checkUint256(amount *big.Int) error {
+	if amount.Sign() < 0 || amount.BitLen() > 256 {
+		return fmt.Errorf("amount %s does not fit in uint256", amount)
+	}
+	return nil
+}
+
+// sendTokenCall sends a zero-value transaction calling the token contract
+func (c *Client) sendTokenCall(from accounts.Account, tokenAddr common.Address, data []byte, signFn SignerFn) (string, error) {
+	return c.sendWithNonce(from, signFn, func(store *nonceStore) (*types.Transaction, error) {
+		return c.buildTokenCall(store, from.Address, tokenAddr, data)
+	})
+}
+
+// buildTokenCall builds a zero-value transaction calling the token contract, taking the
+// nonce from store (see nextNonce)
+func (c *Client) buildTokenCall(store *nonceStore, from common.Address, tokenAddr common.Address, data []byte) (*types.Transaction, error) {
+	gasLimit, err := c.tokenCallGas(from, tokenAddr, data)
 	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %w", err)
+		return nil, err
 	}
 
-	return signedTx.Hash().Hex(), nil
+	// Note: 'To' is the Token Address, 'Value' is 0 (ETH), 'Data' contains the call details
+	return c.buildTx(store, from, tokenAddr, big.NewInt(0), data, gasLimit)
+}
+
+// tokenCallGas returns c.GasLimit if set, else the estimated gas of a token call. A failed
+// estimate usually means the call would revert, so it is not sent with a guessed limit.
+func (c *Client) tokenCallGas(from common.Address, tokenAddr common.Address, data []byte) (uint64, error) {
+	if c.GasLimit != 0 {
+		return c.GasLimit, nil
+	}
+	return c.estimateTxGas(from, tokenAddr, big.NewInt(0), data)
 }
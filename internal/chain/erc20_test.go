@@ -0,0 +1,302 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"tokit/internal/config"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeNode serves the eth_ methods tokit uses for a single USDT-style token, which reverts
+// approve(spender, x) when both x and the current allowance are non-zero. Sent transactions
+// stay pending until their receipt is first requested.
+type fakeNode struct {
+	mu        sync.Mutex
+	chainID   *big.Int
+	token     common.Address
+	allowance map[common.Address]*big.Int // by spender
+	block     uint64
+	mined     map[common.Address]uint64 // mined transaction count per sender
+	pending   map[common.Hash]*types.Transaction
+	receipts  map[common.Hash]uint64 // block of each mined transaction
+	sent      []*types.Transaction
+
+	estimateErr error // returned by every eth_estimateGas when set
+}
+
+type fakeCallArgs struct {
+	From  *common.Address `json:"from"`
+	To    *common.Address `json:"to"`
+	Input *hexutil.Bytes  `json:"input"`
+	Data  *hexutil.Bytes  `json:"data"`
+}
+
+func (a fakeCallArgs) data() []byte {
+	if a.Input != nil {
+		return *a.Input
+	}
+	if a.Data != nil {
+		return *a.Data
+	}
+	return nil
+}
+
+var errReverted = errors.New("execution reverted")
+
+func (n *fakeNode) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(n.chainID)
+}
+
+func (n *fakeNode) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1_000_000_000))
+}
+
+func (n *fakeNode) BlockNumber() hexutil.Uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return hexutil.Uint64(n.block)
+}
+
+func (n *fakeNode) GetTransactionCount(addr common.Address, block rpc.BlockNumberOrHash) hexutil.Uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	count := n.mined[addr]
+	if number, ok := block.Number(); ok && number == rpc.PendingBlockNumber {
+		for _, tx := range n.pending {
+			if sender, _ := types.Sender(types.LatestSignerForChainID(n.chainID), tx); sender == addr {
+				count++
+			}
+		}
+	}
+	return hexutil.Uint64(count)
+}
+
+func (n *fakeNode) Call(args fakeCallArgs, block *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	data := args.data()
+	if len(data) == 4+32+32 && bytes.Equal(data[:4], allowanceMethodID) {
+		allowance := n.allowanceOf(common.BytesToAddress(data[36:68]))
+		return common.LeftPadBytes(allowance.Bytes(), 32), nil
+	}
+	return nil, errReverted
+}
+
+func (n *fakeNode) EstimateGas(args fakeCallArgs, block *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.estimateErr != nil {
+		return 0, n.estimateErr
+	}
+	if err := n.checkApprove(args.data()); err != nil {
+		return 0, err
+	}
+	return 46000, nil
+}
+
+func (n *fakeNode) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.pending[tx.Hash()] = tx
+	n.sent = append(n.sent, tx)
+	return tx.Hash(), nil
+}
+
+func (n *fakeNode) GetTransactionReceipt(hash common.Hash) (map[string]interface{}, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if tx, ok := n.pending[hash]; ok {
+		// Mine it
+		delete(n.pending, hash)
+		sender, err := types.Sender(types.LatestSignerForChainID(n.chainID), tx)
+		if err != nil {
+			return nil, err
+		}
+		n.block++
+		n.mined[sender]++
+		n.receipts[hash] = n.block
+		if err := n.checkApprove(tx.Data()); err == nil {
+			n.allowance[common.BytesToAddress(tx.Data()[4:36])] = new(big.Int).SetBytes(tx.Data()[36:68])
+		}
+	}
+
+	block, ok := n.receipts[hash]
+	if !ok {
+		return nil, nil
+	}
+	return map[string]interface{}{
+		"transactionHash":   hash,
+		"blockNumber":       hexutil.Uint64(block),
+		"blockHash":         common.Hash{1},
+		"status":            hexutil.Uint64(types.ReceiptStatusSuccessful),
+		"gasUsed":           hexutil.Uint64(46000),
+		"cumulativeGasUsed": hexutil.Uint64(46000),
+		"logsBloom":         types.Bloom{},
+		"logs":              []*types.Log{},
+	}, nil
+}
+
+func (n *fakeNode) GetTransactionByHash(hash common.Hash) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (n *fakeNode) allowanceOf(spender common.Address) *big.Int {
+	if allowance, ok := n.allowance[spender]; ok {
+		return allowance
+	}
+	return new(big.Int)
+}
+
+// checkApprove reverts like USDT when an approve changes one non-zero allowance to another
+func (n *fakeNode) checkApprove(data []byte) error {
+	if len(data) != 4+32+32 || !bytes.Equal(data[:4], approveMethodID) {
+		return nil
+	}
+	amount := new(big.Int).SetBytes(data[36:68])
+	if amount.Sign() != 0 && n.allowanceOf(common.BytesToAddress(data[4:36])).Sign() != 0 {
+		return errReverted
+	}
+	return nil
+}
+
+func newFakeClient(t *testing.T, node *fakeNode) *Client {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir()) // nonce store
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", node); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	return &Client{
+		EthClient: ethclient.NewClient(rpc.DialInProc(server)),
+		ChainID:   node.chainID,
+		Config:    config.NetworkConfig{TxType: TxTypeLegacy},
+	}
+}
+
+func keySigner(key *ecdsa.PrivateKey) SignerFn {
+	return func(_ accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+	}
+}
+
+func TestApproveWithReset(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := accounts.Account{Address: crypto.PubkeyToAddress(key.PublicKey)}
+	spender := common.HexToAddress("0x000000000000000000000000000000000000bEEF")
+
+	node := &fakeNode{
+		chainID:   big.NewInt(1337),
+		token:     common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"),
+		allowance: map[common.Address]*big.Int{spender: big.NewInt(100)},
+		mined:     make(map[common.Address]uint64),
+		pending:   make(map[common.Hash]*types.Transaction),
+		receipts:  make(map[common.Hash]uint64),
+	}
+	client := newFakeClient(t, node)
+	token := node.token.Hex()
+	amount := big.NewInt(500)
+
+	needsReset, err := client.ApproveNeedsReset(owner.Address, token, spender.Hex(), amount)
+	if err != nil {
+		t.Fatalf("ApproveNeedsReset: %v", err)
+	}
+	if !needsReset {
+		t.Fatal("ApproveNeedsReset = false, want true for a non-zero allowance")
+	}
+
+	var resetHash string
+	txHash, err := client.ApproveWithReset(owner, token, spender.Hex(), amount, keySigner(key), func(hash string) {
+		resetHash = hash
+	})
+	if err != nil {
+		t.Fatalf("ApproveWithReset: %v", err)
+	}
+
+	if len(node.sent) != 2 {
+		t.Fatalf("sent %d transactions, want 2", len(node.sent))
+	}
+	reset, approve := node.sent[0], node.sent[1]
+	if reset.Hash().Hex() != resetHash || approve.Hash().Hex() != txHash {
+		t.Fatalf("hashes %s, %s do not match the sent transactions", resetHash, txHash)
+	}
+	if reset.Nonce() != 0 || approve.Nonce() != 1 {
+		t.Fatalf("nonces %d, %d, want 0, 1", reset.Nonce(), approve.Nonce())
+	}
+	if got := new(big.Int).SetBytes(reset.Data()[36:68]); got.Sign() != 0 {
+		t.Fatalf("first approve is for %s, want 0", got)
+	}
+
+	if _, err := client.WaitForReceipt(txHash, 1, 0, nil); err != nil {
+		t.Fatalf("WaitForReceipt: %v", err)
+	}
+	if got := node.allowanceOf(spender); got.Cmp(amount) != 0 {
+		t.Fatalf("allowance = %s, want %s", got, amount)
+	}
+}
+
+func TestApproveNeedsResetRPCError(t *testing.T) {
+	spender := common.HexToAddress("0x000000000000000000000000000000000000bEEF")
+	node := &fakeNode{
+		chainID:     big.NewInt(1337),
+		token:       common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"),
+		allowance:   map[common.Address]*big.Int{spender: big.NewInt(100)},
+		estimateErr: errors.New("429 Too Many Requests"),
+	}
+	client := newFakeClient(t, node)
+
+	// A failing RPC is not a reset signal
+	needsReset, err := client.ApproveNeedsReset(common.Address{1}, node.token.Hex(), spender.Hex(), big.NewInt(500))
+	if err == nil || needsReset {
+		t.Fatalf("ApproveNeedsReset = %v, %v; want the RPC error", needsReset, err)
+	}
+}
+
+func TestSanitizeTokenString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "USDC", "USDC"},
+		{"unicode", "Ünïcode Tøken 🚀", "Ünïcode Tøken 🚀"},
+		{"surrounding space", "  DAI\x00\x00", "DAI"},
+		{"escape sequence", "USDT\x1b[2K\r\x1b[1AApproved", "USDT[2K[1AApproved"},
+		{"newline", "Wrapped\nEther", "WrappedEther"},
+		{"bidi override", "US\u202eDC", "USDC"},
+		{"invalid UTF-8", "SAI\xff\xfe", "SAI"},
+		{"too long", strings.Repeat("ab", 40), strings.Repeat("ab", 32)},
+		{"too long multibyte", strings.Repeat("€", 70), strings.Repeat("€", 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeTokenString(tt.in); got != tt.want {
+				t.Fatalf("sanitizeTokenString(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
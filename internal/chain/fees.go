@@ -0,0 +1,178 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Fee presets, from cheapest to fastest
+const (
+	SpeedSlow   = "slow"
+	SpeedNormal = "normal"
+	SpeedFast   = "fast"
+)
+
+// Speeds lists the fee presets in order
+var Speeds = []string{SpeedSlow, SpeedNormal, SpeedFast}
+
+// speedPercentiles are the eth_feeHistory reward percentiles the presets' tips are taken from
+var speedPercentiles = map[string]float64{
+	SpeedSlow:   10,
+	SpeedNormal: 50,
+	SpeedFast:   90,
+}
+
+// feeHistoryBlocks is the number of recent blocks the presets are computed from
+const feeHistoryBlocks = 20
+
+// FeeOptions selects the fees of new transactions. Explicit fees take precedence over the
+// preset; an empty Speed uses the network's default speed.
+type FeeOptions struct {
+	Speed       string
+	MaxFee      *big.Int // wei
+	PriorityFee *big.Int // wei
+}
+
+// Fees are the EIP-1559 fee fields of a transaction. For legacy and access list
+// transactions both hold the gas price.
+type Fees struct {
+	MaxFee      *big.Int
+	PriorityFee *big.Int
+}
+
+// FeeLandscape summarizes recent fee market data
+type FeeLandscape struct {
+	OldestBlock  uint64
+	NextBaseFee  *big.Int
+	MinBaseFee   *big.Int
+	MaxBaseFee   *big.Int
+	GasUsedRatio float64 // average over the blocks
+	Presets      map[string]Fees
+}
+
+// GetFeeLandscape returns the next block's base fee, the recent base fee range and the fees
+// of every preset
+func (c *Client) GetFeeLandscape() (*FeeLandscape, error) {
+	percentiles := make([]float64, len(Speeds))
+	for i, speed := range Speeds {
+		percentiles[i] = speedPercentiles[speed]
+	}
+
+	history, err := c.EthClient.FeeHistory(context.Background(), feeHistoryBlocks, nil, percentiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, fmt.Errorf("empty fee history")
+	}
+
+	// BaseFee has one more entry than there are blocks: the base fee of the next block
+	landscape := &FeeLandscape{
+		OldestBlock: history.OldestBlock.Uint64(),
+		NextBaseFee: history.BaseFee[len(history.BaseFee)-1],
+		MinBaseFee:  history.BaseFee[0],
+		MaxBaseFee:  history.BaseFee[0],
+		Presets:     make(map[string]Fees),
+	}
+	for _, baseFee := range history.BaseFee {
+		if baseFee.Cmp(landscape.MinBaseFee) < 0 {
+			landscape.MinBaseFee = baseFee
+		}
+		if baseFee.Cmp(landscape.MaxBaseFee) > 0 {
+			landscape.MaxBaseFee = baseFee
+		}
+	}
+	for _, ratio := range history.GasUsedRatio {
+		landscape.GasUsedRatio += ratio / float64(len(history.GasUsedRatio))
+	}
+
+	var fallbackTip *big.Int
+	for i, speed := range Speeds {
+		tip := medianReward(history.Reward, i)
+		if tip == nil {
+			// No transactions in the recent blocks to learn from
+			if fallbackTip == nil {
+				if fallbackTip, err = c.EthClient.SuggestGasTipCap(context.Background()); err != nil {
+					return nil, fmt.Errorf("failed to get gas tip cap: %w", err)
+				}
+			}
+			tip = fallbackTip
+		}
+		landscape.Presets[speed] = Fees{
+			MaxFee:      maxFeeFor(landscape.NextBaseFee, tip),
+			PriorityFee: tip,
+		}
+	}
+	return landscape, nil
+}
+
+// SuggestFees returns the fees of new transactions according to c.Fees
+func (c *Client) SuggestFees() (*Fees, error) {
+	speed := c.Fees.Speed
+	if speed == "" {
+		speed = c.Config.Speed
+	}
+	if speed == "" {
+		speed = SpeedNormal
+	}
+	if _, ok := speedPercentiles[speed]; !ok {
+		return nil, fmt.Errorf("unknown speed %q (expected slow, normal or fast)", speed)
+	}
+
+	txType, err := c.TxType()
+	if err != nil {
+		return nil, err
+	}
+	if txType != TxTypeDynamic {
+		return c.suggestGasPrice(speed)
+	}
+
+	fees := &Fees{MaxFee: c.Fees.MaxFee, PriorityFee: c.Fees.PriorityFee}
+	if fees.MaxFee == nil || fees.PriorityFee == nil {
+		landscape, err := c.GetFeeLandscape()
+		if err != nil {
+			return nil, err
+		}
+		preset := landscape.Presets[speed]
+
+		switch {
+		case fees.PriorityFee == nil && fees.MaxFee == nil:
+			fees = &preset
+		case fees.MaxFee == nil:
+			fees.MaxFee = maxFeeFor(landscape.NextBaseFee, fees.PriorityFee)
+		default:
+			fees.PriorityFee = preset.PriorityFee
+			if fees.PriorityFee.Cmp(fees.MaxFee) > 0 {
+				fees.PriorityFee = fees.MaxFee
+			}
+		}
+	}
+
+	if fees.PriorityFee.Cmp(fees.MaxFee) > 0 {
+		return nil, fmt.Errorf("priority fee must not exceed max fee")
+	}
+	return fees, nil
+}
+
+// maxFeeFor leaves room for the base fee to double before the transaction is priced out
+func maxFeeFor(baseFee, tip *big.Int) *big.Int {
+	return new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+}
+
+// medianReward returns the median over the blocks of the reward at percentile index i,
+// skipping empty blocks, or nil if all blocks were empty
+func medianReward(rewards [][]*big.Int, i int) *big.Int {
+	var values []*big.Int
+	for _, block := range rewards {
+		if i < len(block) && block[i] != nil && block[i].Sign() > 0 {
+			values = append(values, block[i])
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Slice(values, func(a, b int) bool { return values[a].Cmp(values[b]) < 0 })
+	return values[len(values)/2]
+}
@@ -2,14 +2,27 @@ package chain
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
-// EstimateGas calculates the gas limit for a transaction
+const (
+	// defaultGasBuffer is the percentage added to gas estimates unless the network sets
+	// gas_buffer
+	defaultGasBuffer = 10
+
+	// transferGas is the gas used by a native transfer to an account without code
+	transferGas = 21000
+)
+
+// EstimateGas calculates the gas limit for a transaction: the node's estimate plus the
+// network's gas buffer
 func (c *Client) EstimateGas(from, to common.Address, value *big.Int, data []byte) (uint64, error) {
 	msg := ethereum.CallMsg{
 		From:     from,
@@ -25,6 +38,76 @@ func (c *Client) EstimateGas(from, to common.Address, value *big.Int, data []byt
 		return 0, fmt.Errorf("failed to estimate gas: %w", err)
 	}
 
-	// Add a buffer (e.g., 10%) to be safe
-	return gasLimit + (gasLimit / 10), nil
+	return c.addGasBuffer(gasLimit, data), nil
+}
+
+// estimateTxGas estimates the gas limit of a transaction as buildTx sends it. On access
+// list networks the list changes the cost, so the larger of both estimates is used.
+func (c *Client) estimateTxGas(from, to common.Address, value *big.Int, data []byte) (uint64, error) {
+	gasLimit, err := c.EstimateGas(from, to, value, data)
+	if err != nil {
+		return 0, err
+	}
+
+	txType, err := c.TxType()
+	if err != nil {
+		return 0, err
+	}
+	if txType == TxTypeAccessList {
+		_, gasUsed, err := c.CreateAccessList(from, to, value, data)
+		if err != nil {
+			return 0, err
+		}
+		if withList := c.addGasBuffer(gasUsed, data); withList > gasLimit {
+			gasLimit = withList
+		}
+	}
+	return gasLimit, nil
+}
+
+// EstimateTransferGas returns the gas limit of a native transfer, or c.GasLimit if set.
+// Transfers to contracts (a Safe, WETH deposits) run code and need more than 21000.
+func (c *Client) EstimateTransferGas(from common.Address, to string, value *big.Int) (uint64, error) {
+	if c.GasLimit != 0 {
+		return c.GasLimit, nil
+	}
+	gasLimit, err := c.estimateTxGas(from, common.HexToAddress(to), value, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%w (set one with --gas-limit)", err)
+	}
+	return gasLimit, nil
+}
+
+// EstimateTokenTransferGas returns the gas limit of a token transfer, as used by
+// SendTokenTransaction
+func (c *Client) EstimateTokenTransferGas(from common.Address, tokenAddress, to string, amount *big.Int) (uint64, error) {
+	data, err := transferData(common.HexToAddress(to), amount)
+	if err != nil {
+		return 0, err
+	}
+	return c.tokenCallGas(from, common.HexToAddress(tokenAddress), data)
+}
+
+// isRevert reports whether an eth_estimateGas or eth_call error is the contract reverting,
+// as opposed to an RPC, network or rate-limit failure
+func isRevert(err error) bool {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "revert")
+}
+
+// addGasBuffer adds the network's gas buffer to a gas estimate
+func (c *Client) addGasBuffer(gas uint64, data []byte) uint64 {
+	// A transfer that runs no code always uses exactly 21000
+	if gas == transferGas && len(data) == 0 {
+		return gas
+	}
+
+	buffer := uint64(defaultGasBuffer)
+	if c.Config.GasBuffer != nil && *c.Config.GasBuffer >= 0 {
+		buffer = uint64(*c.Config.GasBuffer)
+	}
+	return gas + gas*buffer/100
 }
@@ -0,0 +1,236 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gofrs/flock"
+)
+
+const (
+	// nonceLockTimeout is how long a send waits for another tokit process holding the nonce lock
+	nonceLockTimeout = 30 * time.Second
+
+	// nonceGapGrace is how long a sent transaction may be unknown to the node before it is
+	// considered dropped; load-balanced RPCs take a moment to propagate new transactions
+	nonceGapGrace = time.Minute
+)
+
+// NonceGapError is returned when transactions tracked as in flight are unknown to the node,
+// usually because they were dropped from the mempool. Later nonces would never be mined.
+type NonceGapError struct {
+	Expected uint64 // nonce the node expects next
+	Tracked  uint64 // nonce tokit would use next
+}
+
+func (e *NonceGapError) Error() string {
+	return fmt.Sprintf("nonce gap: the node expects nonce %d but tokit tracked transactions up to %d that the node does not know (dropped?); "+
+		"resend them or run 'tokit tx nonce <chain> --reset'", e.Expected, e.Tracked-1)
+}
+
+// InFlightTx is a transaction sent by tokit that was not mined yet when last checked
+type InFlightTx struct {
+	Nonce uint64
+	Hash  string
+	Known bool // whether the node still has the transaction
+}
+
+// NonceStatus compares the nonces the node reports with the ones tracked locally
+type NonceStatus struct {
+	Confirmed uint64 // nonce of the next transaction to be mined
+	Pending   uint64 // next nonce according to the node, including its mempool
+	Next      uint64 // nonce tokit will use for the next transaction
+	InFlight  []InFlightTx
+	Gap       bool
+}
+
+// nonceStore tracks the nonces of transactions sent by tokit per chain and address in
+// ~/.tokit/nonces.json. It is guarded by a file lock so concurrent tokit processes
+// never hand out the same nonce.
+type nonceStore struct {
+	path     string
+	lock     *flock.Flock
+	Accounts map[string]*nonceState `json:"accounts"`
+}
+
+type nonceState struct {
+	Next     uint64                   `json:"next"`
+	InFlight map[uint64]inFlightEntry `json:"in_flight,omitempty"`
+}
+
+type inFlightEntry struct {
+	Hash string    `json:"hash"`
+	Sent time.Time `json:"sent"`
+}
+
+// lockNonces acquires the nonce lock and loads the store; callers must unlock it
+func lockNonces() (*nonceStore, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(home, ".tokit")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	lock := flock.New(filepath.Join(dir, "nonces.lock"))
+	ctx, cancel := context.WithTimeout(context.Background(), nonceLockTimeout)
+	defer cancel()
+	if locked, err := lock.TryLockContext(ctx, 100*time.Millisecond); !locked {
+		return nil, fmt.Errorf("failed to lock nonce store: %v", err)
+	}
+
+	store := &nonceStore{
+		path:     filepath.Join(dir, "nonces.json"),
+		lock:     lock,
+		Accounts: make(map[string]*nonceState),
+	}
+	data, err := os.ReadFile(store.path)
+	if err != nil && !os.IsNotExist(err) {
+		store.unlock()
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, store); err != nil {
+			store.unlock()
+			return nil, fmt.Errorf("invalid nonce store %s: %w", store.path, err)
+		}
+		if store.Accounts == nil {
+			store.Accounts = make(map[string]*nonceState)
+		}
+	}
+	return store, nil
+}
+
+func (s *nonceStore) unlock() {
+	s.lock.Unlock()
+}
+
+func (s *nonceStore) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+func nonceKey(chainID int64, addr common.Address) string {
+	return fmt.Sprintf("%d/%s", chainID, addr.Hex())
+}
+
+func (s *nonceStore) state(chainID int64, addr common.Address) *nonceState {
+	key := nonceKey(chainID, addr)
+	st, ok := s.Accounts[key]
+	if !ok {
+		st = &nonceState{}
+		s.Accounts[key] = st
+	}
+	if st.InFlight == nil {
+		st.InFlight = make(map[uint64]inFlightEntry)
+	}
+	return st
+}
+
+// record marks a transaction as sent
+func (s *nonceStore) record(chainID int64, addr common.Address, nonce uint64, txHash string) error {
+	st := s.state(chainID, addr)
+	st.InFlight[nonce] = inFlightEntry{Hash: txHash, Sent: time.Now()}
+	if nonce+1 > st.Next {
+		st.Next = nonce + 1
+	}
+	return s.save()
+}
+
+// nextNonce returns the nonce for the next transaction from addr. The node's pending nonce
+// lags behind on load-balanced RPCs, so nonces of transactions that are still in flight are
+// skipped, unless the node has lost them, which is reported as a NonceGapError.
+// It uses the store locked by sendWithNonce, or locks it briefly when store is nil, for
+// transactions that are only built.
+func (c *Client) nextNonce(store *nonceStore, addr common.Address) (uint64, error) {
+	if store == nil {
+		var err error
+		if store, err = lockNonces(); err != nil {
+			return 0, err
+		}
+		defer store.unlock()
+	}
+
+	status, err := c.nonceStatus(store, addr)
+	if err != nil {
+		return 0, err
+	}
+	if status.Gap {
+		return 0, &NonceGapError{Expected: status.Pending, Tracked: status.Next}
+	}
+	return status.Next, nil
+}
+
+func (c *Client) nonceStatus(store *nonceStore, addr common.Address) (*NonceStatus, error) {
+	ctx := context.Background()
+
+	confirmed, err := c.EthClient.NonceAt(ctx, addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	pending, err := c.EthClient.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	st := store.state(c.ChainID.Int64(), addr)
+	for nonce := range st.InFlight {
+		if nonce < confirmed {
+			delete(st.InFlight, nonce)
+		}
+	}
+	if len(st.InFlight) == 0 || st.Next < pending {
+		st.Next = max(pending, confirmed)
+	}
+
+	status := &NonceStatus{Confirmed: confirmed, Pending: pending, Next: st.Next}
+	for nonce, entry := range st.InFlight {
+		_, _, err := c.EthClient.TransactionByHash(ctx, common.HexToHash(entry.Hash))
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("failed to get transaction %s: %w", entry.Hash, err)
+		}
+		known := err == nil
+		status.InFlight = append(status.InFlight, InFlightTx{Nonce: nonce, Hash: entry.Hash, Known: known})
+		if !known && nonce >= pending && time.Since(entry.Sent) > nonceGapGrace {
+			status.Gap = true
+		}
+	}
+	sort.Slice(status.InFlight, func(i, j int) bool { return status.InFlight[i].Nonce < status.InFlight[j].Nonce })
+	return status, store.save()
+}
+
+// NonceStatus reports the node's nonces for addr next to the locally tracked ones
+func (c *Client) NonceStatus(addr common.Address) (*NonceStatus, error) {
+	store, err := lockNonces()
+	if err != nil {
+		return nil, err
+	}
+	defer store.unlock()
+	return c.nonceStatus(store, addr)
+}
+
+// ResetNonce forgets the locally tracked nonces of addr so the next transaction uses the
+// node's pending nonce
+func (c *Client) ResetNonce(addr common.Address) error {
+	store, err := lockNonces()
+	if err != nil {
+		return err
+	}
+	defer store.unlock()
+
+	delete(store.Accounts, nonceKey(c.ChainID.Int64(), addr))
+	return store.save()
+}
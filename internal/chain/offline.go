@@ -0,0 +1,89 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// UnsignedTx is the file written by 'tx build' and signed offline by 'tx sign'. Token
+// is only set for token transfers so amounts can be shown without an RPC connection.
+// ChainID is kept outside of Tx because unsigned legacy transactions do not carry one.
+type UnsignedTx struct {
+	Network string             `json:"network"`
+	ChainID *big.Int           `json:"chain_id"`
+	From    common.Address     `json:"from"`
+	Token   *TokenInfo         `json:"token,omitempty"`
+	Tx      *types.Transaction `json:"tx"`
+}
+
+// WriteUnsignedTx saves an unsigned transaction as JSON to a new file; it never
+// overwrites an existing one
+func WriteUnsignedTx(path string, utx *UnsignedTx) error {
+	data, err := json.MarshalIndent(utx, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadUnsignedTx loads a transaction saved by WriteUnsignedTx
+func ReadUnsignedTx(path string) (*UnsignedTx, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var utx UnsignedTx
+	if err := json.Unmarshal(data, &utx); err != nil {
+		return nil, fmt.Errorf("invalid unsigned transaction file: %w", err)
+	}
+	if utx.Tx == nil {
+		return nil, fmt.Errorf("invalid unsigned transaction file: missing tx")
+	}
+	if utx.ChainID == nil && utx.Tx.Type() != types.LegacyTxType {
+		utx.ChainID = utx.Tx.ChainId()
+	}
+	if utx.ChainID == nil || utx.ChainID.Sign() == 0 {
+		return nil, fmt.Errorf("invalid unsigned transaction file: missing chain ID")
+	}
+	if utx.Token != nil {
+		utx.Token.sanitize()
+	}
+	return &utx, nil
+}
+
+// EncodeSignedTx returns the 0x-prefixed raw encoding of a signed transaction
+func EncodeSignedTx(tx *types.Transaction) (string, error) {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(raw), nil
+}
+
+// DecodeSignedTx parses a 0x-prefixed raw signed transaction
+func DecodeSignedTx(raw string) (*types.Transaction, error) {
+	data, err := hexutil.Decode(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid raw transaction: %w", err)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("invalid raw transaction: %w", err)
+	}
+	return tx, nil
+}
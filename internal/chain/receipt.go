@@ -0,0 +1,156 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// receiptPollInterval is how often WaitForReceipt polls the node
+	receiptPollInterval = 3 * time.Second
+
+	// receiptMaxFailures is how many polls in a row may fail with RPC errors before
+	// WaitForReceipt gives up
+	receiptMaxFailures = 5
+)
+
+var (
+	// ErrTxDropped is returned by WaitForReceipt when the node no longer knows the transaction
+	ErrTxDropped = errors.New("transaction was dropped: the node does not know it")
+
+	// ErrTxReplaced is returned by WaitForReceipt when another transaction with the same
+	// nonce was mined, for example after 'tx speedup' or 'tx cancel'
+	ErrTxReplaced = errors.New("transaction was replaced by another transaction with the same nonce")
+)
+
+// receiptWatch is what WaitForReceipt knows about a transaction that is not mined yet
+type receiptWatch struct {
+	tx           *types.Transaction
+	sender       common.Address
+	unknownSince time.Time
+}
+
+// WaitForReceipt polls until the transaction is mined and has the given number of
+// confirmations (the including block counts as the first). It gives up after timeout
+// (none if 0), when the transaction is dropped or replaced, or after repeated RPC errors.
+// progress is called with the current confirmation count while waiting and may be nil.
+func (c *Client) WaitForReceipt(txHash string, confirmations uint64, timeout time.Duration, progress func(confirmed uint64)) (*types.Receipt, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	hash := common.HexToHash(txHash)
+	if confirmations == 0 {
+		confirmations = 1
+	}
+
+	var (
+		watch        receiptWatch
+		lastReported uint64
+		failures     int
+	)
+	for {
+		receipt, err := c.EthClient.TransactionReceipt(ctx, hash)
+		switch {
+		case errors.Is(err, ethereum.NotFound):
+			// Not mined yet, or dropped from its block by a reorg
+			err = c.checkNotMined(ctx, hash, &watch)
+		case err == nil:
+			var latest uint64
+			if latest, err = c.EthClient.BlockNumber(ctx); err != nil {
+				err = fmt.Errorf("failed to get latest block: %w", err)
+				break
+			}
+			var confirmed uint64
+			if mined := receipt.BlockNumber.Uint64(); latest >= mined {
+				confirmed = latest - mined + 1
+			}
+			if confirmed >= confirmations {
+				return receipt, nil
+			}
+			if progress != nil && confirmed != lastReported {
+				progress(confirmed)
+				lastReported = confirmed
+			}
+		default:
+			err = fmt.Errorf("failed to get receipt: %w", err)
+		}
+
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("timed out after %s waiting for %s", timeout, txHash)
+		}
+		if errors.Is(err, ErrTxDropped) || errors.Is(err, ErrTxReplaced) {
+			return nil, err
+		}
+		if err != nil {
+			if failures++; failures >= receiptMaxFailures {
+				return nil, err
+			}
+		} else {
+			failures = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out after %s waiting for %s", timeout, txHash)
+		case <-time.After(receiptPollInterval):
+		}
+	}
+}
+
+// checkNotMined looks for signs that a transaction without a receipt will never be mined:
+// the node forgot it (for longer than load-balanced RPCs take to propagate it), or the
+// sender's mined nonce moved past it.
+func (c *Client) checkNotMined(ctx context.Context, hash common.Hash, watch *receiptWatch) error {
+	tx, _, err := c.EthClient.TransactionByHash(ctx, hash)
+	switch {
+	case errors.Is(err, ethereum.NotFound):
+		if watch.unknownSince.IsZero() {
+			watch.unknownSince = time.Now()
+		}
+		if watch.tx == nil {
+			if time.Since(watch.unknownSince) > nonceGapGrace {
+				return ErrTxDropped
+			}
+			return nil
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get transaction: %w", err)
+	default:
+		watch.unknownSince = time.Time{}
+		if watch.tx == nil {
+			sender, err := types.Sender(types.LatestSignerForChainID(c.ChainID), tx)
+			if err != nil {
+				return fmt.Errorf("failed to get sender: %w", err)
+			}
+			watch.tx, watch.sender = tx, sender
+		}
+	}
+
+	mined, err := c.EthClient.NonceAt(ctx, watch.sender, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+	if mined <= watch.tx.Nonce() {
+		if !watch.unknownSince.IsZero() && time.Since(watch.unknownSince) > nonceGapGrace {
+			return ErrTxDropped
+		}
+		return nil
+	}
+
+	// The nonce is used; make sure the transaction was not mined since the receipt check
+	if _, err := c.EthClient.TransactionReceipt(ctx, hash); err == nil {
+		return nil
+	} else if !errors.Is(err, ethereum.NotFound) {
+		return fmt.Errorf("failed to get receipt: %w", err)
+	}
+	return ErrTxReplaced
+}
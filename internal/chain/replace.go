@@ -0,0 +1,110 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// replacementBump is the minimum fee increase in percent that nodes require to replace a
+// pending transaction
+const replacementBump = 10
+
+// PendingTransaction loads a transaction that has not been mined yet and returns it with
+// its sender
+func (c *Client) PendingTransaction(txHash string) (*types.Transaction, common.Address, error) {
+	tx, isPending, err := c.EthClient.TransactionByHash(context.Background(), common.HexToHash(txHash))
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if !isPending {
+		return nil, common.Address{}, errors.New("transaction is already mined")
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(c.ChainID), tx)
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("failed to get sender: %w", err)
+	}
+	return tx, sender, nil
+}
+
+// BuildReplacement builds a transaction with the same nonce and type as the pending one and
+// a tip and fee cap (or gas price) raised by at least 10%, or to the current fees (see
+// SuggestFees) if higher. With cancel set, it is a 0-value transfer to the sender instead
+// of a copy.
+func (c *Client) BuildReplacement(pending *types.Transaction, from common.Address, cancel bool) (*types.Transaction, error) {
+	fees, err := c.SuggestFees()
+	if err != nil {
+		return nil, err
+	}
+
+	gas, to, value, data, accessList := pending.Gas(), pending.To(), pending.Value(), pending.Data(), pending.AccessList()
+	if cancel {
+		gas, to, value, data, accessList = transferGas, &from, big.NewInt(0), nil, nil
+	}
+
+	switch pending.Type() {
+	case types.LegacyTxType:
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    pending.Nonce(),
+			GasPrice: maxBig(fees.MaxFee, bumpFee(pending.GasPrice())),
+			Gas:      gas,
+			To:       to,
+			Value:    value,
+			Data:     data,
+		}), nil
+
+	case types.AccessListTxType:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    c.ChainID,
+			Nonce:      pending.Nonce(),
+			GasPrice:   maxBig(fees.MaxFee, bumpFee(pending.GasPrice())),
+			Gas:        gas,
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: accessList,
+		}), nil
+	}
+
+	gasTipCap := maxBig(fees.PriorityFee, bumpFee(pending.GasTipCap()))
+	gasFeeCap := maxBig(fees.MaxFee, bumpFee(pending.GasFeeCap()))
+	if gasFeeCap.Cmp(gasTipCap) < 0 {
+		gasFeeCap = gasTipCap
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:    c.ChainID,
+		Nonce:      pending.Nonce(),
+		GasTipCap:  gasTipCap,
+		GasFeeCap:  gasFeeCap,
+		Gas:        gas,
+		To:         to,
+		Value:      value,
+		Data:       data,
+		AccessList: accessList,
+	}), nil
+}
+
+// SendReplacement signs and broadcasts a transaction built by BuildReplacement
+func (c *Client) SendReplacement(from accounts.Account, replacement *types.Transaction, signFn SignerFn) (string, error) {
+	return c.signAndSend(from, replacement, signFn)
+}
+
+// bumpFee raises a fee by replacementBump percent, rounding up
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+replacementBump))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
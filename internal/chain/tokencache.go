@@ -0,0 +1,73 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// tokenCache persists token metadata per chain in ~/.tokit/cache/tokens-<chainID>.json,
+// since decimals, symbol and name never change once a token is deployed
+type tokenCache struct {
+	path   string
+	Tokens map[common.Address]*TokenInfo `json:"tokens"`
+}
+
+// loadTokenCache loads the cache of a chain. On error it still returns a usable empty
+// cache, which is not saved if its path is unknown.
+func loadTokenCache(chainID int64) (*tokenCache, error) {
+	cache := &tokenCache{Tokens: make(map[common.Address]*TokenInfo)}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return cache, err
+	}
+	cache.path = filepath.Join(home, ".tokit", "cache", fmt.Sprintf("tokens-%d.json", chainID))
+
+	data, err := os.ReadFile(cache.path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return cache, err
+	}
+	if err := json.Unmarshal(data, cache); err != nil {
+		cache.Tokens = make(map[common.Address]*TokenInfo)
+		return cache, fmt.Errorf("invalid token cache %s: %w", cache.path, err)
+	}
+	if cache.Tokens == nil {
+		cache.Tokens = make(map[common.Address]*TokenInfo)
+	}
+	for address, info := range cache.Tokens {
+		if info == nil {
+			delete(cache.Tokens, address)
+			continue
+		}
+		info.sanitize()
+	}
+	return cache, nil
+}
+
+func (t *tokenCache) get(token common.Address) (*TokenInfo, bool) {
+	info, ok := t.Tokens[token]
+	return info, ok
+}
+
+func (t *tokenCache) set(info *TokenInfo) error {
+	t.Tokens[info.Address] = info
+	if t.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, data, 0600)
+}
@@ -5,93 +5,169 @@ import (
 	"fmt"
 	"math/big"
 
+	"tokit/internal/utils"
+
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
-// SendTransaction builds, signs, and sends an EIP-1559 transaction
+// SignerFn signs a transaction for the given account and chain ID
+type SignerFn func(accounts.Account, *types.Transaction, *big.Int) (*types.Transaction, error)
+
+// SendTransaction builds, signs, and sends a native transfer
+// value is the amount in wei
 func (c *Client) SendTransaction(
 	from accounts.Account,
 	to string,
-	amount *big.Float,
-	signFn func(accounts.Account, *types.Transaction, *big.Int) (*types.Transaction, error),
+	value *big.Int,
+	signFn SignerFn,
 ) (string, error) {
-	ctx := context.Background()
+	return c.sendWithNonce(from, signFn, func(store *nonceStore) (*types.Transaction, error) {
+		return c.buildTransfer(store, from.Address, to, value)
+	})
+}
 
-	// 1. Convert amount to Wei
-	weiValue := new(big.Int)
-	amount.Mul(amount, big.NewFloat(1e18)).Int(weiValue)
+// BuildTransaction builds an unsigned native transfer with the sender's next nonce and
+// current fees
+func (c *Client) BuildTransaction(from common.Address, to string, value *big.Int) (*types.Transaction, error) {
+	return c.buildTransfer(nil, from, to, value)
+}
 
-	// 2. Get Nonce
-	nonce, err := c.EthClient.PendingNonceAt(ctx, from.Address)
+// buildTransfer builds a native transfer, taking the nonce from store (see nextNonce)
+func (c *Client) buildTransfer(store *nonceStore, from common.Address, to string, value *big.Int) (*types.Transaction, error) {
+	gasLimit, err := c.EstimateTransferGas(from, to, value)
 	if err != nil {
-		return "", fmt.Errorf("failed to get nonce: %w", err)
+		return nil, err
 	}
+	return c.buildTx(store, from, common.HexToAddress(to), value, nil, gasLimit)
+}
 
-	// 3. Get Gas Tip Cap (Priority Fee)
-	gasTipCap, err := c.EthClient.SuggestGasTipCap(ctx)
+// SendSignedTx broadcasts a signed transaction and returns its hash
+func (c *Client) SendSignedTx(signedTx *types.Transaction) (string, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(signedTx.ChainId()), signedTx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get gas tip cap: %w", err)
+		return "", fmt.Errorf("invalid transaction signature: %w", err)
 	}
 
-	// 4. Get Header for Base Fee
-	head, err := c.EthClient.HeaderByNumber(ctx, nil)
+	store, err := lockNonces()
 	if err != nil {
-		return "", fmt.Errorf("failed to get header: %w", err)
+		return "", err
 	}
+	defer store.unlock()
+	return c.sendSigned(store, from, signedTx)
+}
 
-	// 5. Calculate Gas Fee Cap (Base Fee * 2 + Tip)
-	gasFeeCap := new(big.Int).Add(
-		new(big.Int).Mul(head.BaseFee, big.NewInt(2)),
-		gasTipCap,
-	)
-
-	// 6. Estimate Gas Limit
-	toAddr := common.HexToAddress(to)
-	// msg := map[string]interface{}{
-	// 	"from":  from.Address,
-	// 	"to":    toAddr,
-	// 	"value": weiValue,
-	// }
-	// Note: EstimateGas requires CallMsg, but here we construct it implicitly or use client.EstimateGas
-	// Let's use the proper CallMsg struct
-	// We need to import "github.com/ethereum/go-ethereum" for CallMsg but it's in interfaces
-	// Actually ethclient.EstimateGas takes (ctx, msg) where msg is ethereum.CallMsg
-	// We need to import "github.com/ethereum/go-ethereum"
-
-	// Let's just use a fixed gas limit for standard transfers if estimation fails, or better, implement it properly.
-	// Standard ETH transfer is 21000.
-	gasLimit := uint64(21000)
-
-	// If we want to support contract interactions later, we need proper estimation.
-	// For now, let's stick to 21000 for simple transfers to avoid import cycles or complexity,
-	// but strictly speaking we should estimate.
-	// Let's try to estimate.
-
-	// 7. Create Transaction
-	tx := types.NewTx(&types.DynamicFeeTx{
-		ChainID:   c.ChainID,
-		Nonce:     nonce,
-		GasTipCap: gasTipCap,
-		GasFeeCap: gasFeeCap,
-		Gas:       gasLimit,
-		To:        &toAddr,
-		Value:     weiValue,
-		Data:      nil,
-	})
+// sendWithNonce builds, signs and sends a transaction while holding the nonce lock, so
+// concurrent sends from the same account get consecutive nonces. build gets the locked
+// store to take the nonce from.
+func (c *Client) sendWithNonce(from accounts.Account, signFn SignerFn, build func(*nonceStore) (*types.Transaction, error)) (string, error) {
+	store, err := lockNonces()
+	if err != nil {
+		return "", err
+	}
+	defer store.unlock()
 
-	// 8. Sign Transaction
+	tx, err := build(store)
+	if err != nil {
+		return "", err
+	}
 	signedTx, err := signFn(from, tx, c.ChainID)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign transaction: %w", err)
 	}
+	return c.sendSigned(store, from.Address, signedTx)
+}
 
-	// 9. Send Transaction
-	err = c.EthClient.SendTransaction(ctx, signedTx)
-	if err != nil {
+// sendSigned broadcasts a signed transaction and tracks its nonce as in flight
+func (c *Client) sendSigned(store *nonceStore, from common.Address, signedTx *types.Transaction) (string, error) {
+	if err := c.EthClient.SendTransaction(context.Background(), signedTx); err != nil {
 		return "", fmt.Errorf("failed to send transaction: %w", err)
 	}
+	txHash := signedTx.Hash().Hex()
 
-	return signedTx.Hash().Hex(), nil
+	// Tracking is best effort: the transaction is already sent, and a lost entry only
+	// means the next send falls back to the node's pending nonce
+	if err := store.record(c.ChainID.Int64(), from, signedTx.Nonce(), txHash); err != nil {
+		utils.Log.Warnf("Failed to track nonce %d of %s: %v", signedTx.Nonce(), txHash, err)
+	}
+	return txHash, nil
+}
+
+// buildTx builds an unsigned transaction of the network's type (see TxType) with the
+// given gas limit, taking the nonce from store (see nextNonce)
+func (c *Client) buildTx(
+	store *nonceStore,
+	from common.Address,
+	to common.Address,
+	value *big.Int,
+	data []byte,
+	gasLimit uint64,
+) (*types.Transaction, error) {
+	txType, err := c.TxType()
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Get Nonce
+	nonce, err := c.nextNonce(store, from)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Get Fees (speed preset from fee history, or explicit fees)
+	fees, err := c.SuggestFees()
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Create Transaction
+	switch txType {
+	case TxTypeLegacy:
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: fees.MaxFee,
+			Gas:      gasLimit,
+			To:       &to,
+			Value:    value,
+			Data:     data,
+		}), nil
+
+	case TxTypeAccessList:
+		accessList, _, err := c.CreateAccessList(from, to, value, data)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    c.ChainID,
+			Nonce:      nonce,
+			GasPrice:   fees.MaxFee,
+			Gas:        gasLimit,
+			To:         &to,
+			Value:      value,
+			Data:       data,
+			AccessList: accessList,
+		}), nil
+
+	default:
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   c.ChainID,
+			Nonce:     nonce,
+			GasTipCap: fees.PriorityFee,
+			GasFeeCap: fees.MaxFee,
+			Gas:       gasLimit,
+			To:        &to,
+			Value:     value,
+			Data:      data,
+		}), nil
+	}
+}
+
+// signAndSend signs the transaction with signFn and broadcasts it
+func (c *Client) signAndSend(from accounts.Account, tx *types.Transaction, signFn SignerFn) (string, error) {
+	signedTx, err := signFn(from, tx, c.ChainID)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return c.SendSignedTx(signedTx)
 }
@@ -0,0 +1,137 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Transaction types, set per network with tx_type in the config
+const (
+	TxTypeLegacy     = "legacy"     // pre-EIP-2718 transactions with a gas price
+	TxTypeAccessList = "accesslist" // EIP-2930 transactions with a gas price and an access list
+	TxTypeDynamic    = "dynamic"    // EIP-1559 transactions with a max fee and priority fee
+)
+
+// legacySpeedPercent scales eth_gasPrice per speed preset on chains without EIP-1559,
+// since they have no fee history rewards to take percentiles from. Networks can override
+// it with gas_price_percent. eth_gasPrice is often the minimum the node accepts (BSC and
+// private chains), so no preset goes below it.
+var legacySpeedPercent = map[string]int64{
+	SpeedSlow:   100,
+	SpeedNormal: 110,
+	SpeedFast:   125,
+}
+
+// gasPricePercent returns the percentage of eth_gasPrice a speed preset pays
+func (c *Client) gasPricePercent(speed string) (int64, error) {
+	for name := range c.Config.GasPricePercent {
+		if _, ok := legacySpeedPercent[name]; !ok {
+			return 0, fmt.Errorf("unknown speed %q in gas_price_percent (expected slow, normal or fast)", name)
+		}
+	}
+	percent, ok := c.Config.GasPricePercent[speed]
+	if !ok {
+		return legacySpeedPercent[speed], nil
+	}
+	if percent < 100 {
+		return 0, fmt.Errorf("gas_price_percent for %s is %d; it must be at least 100, the node's gas price", speed, percent)
+	}
+	return percent, nil
+}
+
+// TxType returns the configured transaction type of the network, or detects it from the
+// latest block: chains without a base fee have not activated EIP-1559
+func (c *Client) TxType() (string, error) {
+	if c.txType != "" {
+		return c.txType, nil
+	}
+
+	switch c.Config.TxType {
+	case TxTypeLegacy, TxTypeAccessList, TxTypeDynamic:
+		c.txType = c.Config.TxType
+	case "":
+		head, err := c.EthClient.HeaderByNumber(context.Background(), nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to get header: %w", err)
+		}
+		c.txType = TxTypeDynamic
+		if head.BaseFee == nil {
+			c.txType = TxTypeLegacy
+		}
+	default:
+		return "", fmt.Errorf("unknown tx_type %q (expected legacy, accesslist or dynamic)", c.Config.TxType)
+	}
+	return c.txType, nil
+}
+
+// suggestGasPrice returns the gas price for legacy and access list transactions, as fees
+// with an equal max fee and priority fee, which is how such transactions are priced
+func (c *Client) suggestGasPrice(speed string) (*Fees, error) {
+	if c.Fees.PriorityFee != nil && c.Fees.MaxFee == nil {
+		return nil, fmt.Errorf("transactions on this network have a single gas price; set it with the max fee instead of the priority fee")
+	}
+
+	price := c.Fees.MaxFee
+	if price == nil {
+		percent, err := c.gasPricePercent(speed)
+		if err != nil {
+			return nil, err
+		}
+		suggested, err := c.EthClient.SuggestGasPrice(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get gas price: %w", err)
+		}
+		price = new(big.Int).Mul(suggested, big.NewInt(percent))
+		price.Div(price, big.NewInt(100))
+	}
+	return &Fees{MaxFee: price, PriorityFee: price}, nil
+}
+
+// GetGasPrices returns the gas price of every speed preset on chains without EIP-1559
+func (c *Client) GetGasPrices() (map[string]*big.Int, error) {
+	suggested, err := c.EthClient.SuggestGasPrice(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price: %w", err)
+	}
+	prices := make(map[string]*big.Int)
+	for _, speed := range Speeds {
+		percent, err := c.gasPricePercent(speed)
+		if err != nil {
+			return nil, err
+		}
+		price := new(big.Int).Mul(suggested, big.NewInt(percent))
+		prices[speed] = price.Div(price, big.NewInt(100))
+	}
+	return prices, nil
+}
+
+// CreateAccessList asks the node which addresses and storage slots a call touches
+// (eth_createAccessList) and returns them with the gas the call uses with the list
+func (c *Client) CreateAccessList(from, to common.Address, value *big.Int, data []byte) (types.AccessList, uint64, error) {
+	args := map[string]interface{}{
+		"from":  from,
+		"to":    to,
+		"value": (*hexutil.Big)(value),
+		"data":  hexutil.Bytes(data),
+	}
+	var result struct {
+		AccessList *types.AccessList `json:"accessList"`
+		GasUsed    hexutil.Uint64    `json:"gasUsed"`
+		Error      string            `json:"error"`
+	}
+	if err := c.EthClient.Client().CallContext(context.Background(), &result, "eth_createAccessList", args, "pending"); err != nil {
+		return nil, 0, fmt.Errorf("failed to create access list: %w", err)
+	}
+	if result.Error != "" {
+		return nil, 0, fmt.Errorf("failed to create access list: %s", result.Error)
+	}
+	if result.AccessList == nil {
+		return types.AccessList{}, uint64(result.GasUsed), nil
+	}
+	return *result.AccessList, uint64(result.GasUsed), nil
+}
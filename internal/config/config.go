@@ -14,10 +14,16 @@ type Config struct {
 }
 
 type NetworkConfig struct {
-	RPCURL   string `mapstructure:"rpc_url"`
-	ChainID  int64  `mapstructure:"chain_id"`
-	Symbol   string `mapstructure:"symbol"`
-	Explorer string `mapstructure:"explorer"`
+	RPCURL    string `mapstructure:"rpc_url"`
+	ChainID   int64  `mapstructure:"chain_id"`
+	Symbol    string `mapstructure:"symbol"`
+	Explorer  string `mapstructure:"explorer"`
+	Speed     string `mapstructure:"speed"`      // default fee preset: slow, normal or fast
+	TxType    string `mapstructure:"tx_type"`    // legacy, accesslist or dynamic; detected when empty
+	GasBuffer *int   `mapstructure:"gas_buffer"` // percent added to gas estimates; 10 when unset
+
+	// GasPricePercent scales eth_gasPrice per speed on networks without EIP-1559 (at least 100)
+	GasPricePercent map[string]int64 `mapstructure:"gas_price_percent"`
 }
 
 // LoadConfig loads the configuration from file and environment variables
@@ -0,0 +1,236 @@
+package siwe
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	headerSuffix = " wants you to sign in with your Ethereum account:"
+
+	// Version is the only message version defined by EIP-4361
+	Version = "1"
+)
+
+var nonceRe = regexp.MustCompile(`^[a-zA-Z0-9]{8,}$`)
+
+// Message is a parsed EIP-4361 (Sign-In with Ethereum) message
+type Message struct {
+	Scheme         string
+	Domain         string
+	Address        common.Address
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time
+	NotBefore      *time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// Expectations are the values a message is checked against; empty fields are not checked
+type Expectations struct {
+	Domain  string
+	Nonce   string
+	ChainID int64
+	Time    time.Time
+}
+
+// Parse parses the text of an EIP-4361 message. Messages with control or other
+// non-printable characters are rejected, as they could spoof the confirmation prompt.
+func Parse(text string) (*Message, error) {
+	if !utf8.ValidString(text) {
+		return nil, errors.New("message is not valid UTF-8")
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		for _, r := range line {
+			if !unicode.IsPrint(r) {
+				return nil, fmt.Errorf("non-printable character %q on line %d", r, i+1)
+			}
+		}
+	}
+	p := &parser{lines: lines}
+	m := &Message{}
+
+	header := p.next()
+	if !strings.HasSuffix(header, headerSuffix) {
+		return nil, errors.New("not a Sign-In with Ethereum message: missing header line")
+	}
+	m.Domain = strings.TrimSuffix(header, headerSuffix)
+	if scheme, domain, ok := strings.Cut(m.Domain, "://"); ok {
+		m.Scheme, m.Domain = scheme, domain
+	}
+	if m.Domain == "" {
+		return nil, errors.New("missing domain")
+	}
+
+	address := p.next()
+	if !common.IsHexAddress(address) || !strings.HasPrefix(address, "0x") {
+		return nil, fmt.Errorf("invalid address: %q", address)
+	}
+	m.Address = common.HexToAddress(address)
+	if address != m.Address.Hex() {
+		return nil, fmt.Errorf("address is not EIP-55 checksummed: %s", address)
+	}
+
+	if p.next() != "" {
+		return nil, errors.New("expected an empty line after the address")
+	}
+	// The statement is optional; it is followed by a second empty line either way
+	if line := p.peek(); line != "" && !strings.HasPrefix(line, "URI: ") {
+		m.Statement = p.next()
+	}
+	if p.next() != "" {
+		return nil, errors.New("expected an empty line before the URI")
+	}
+
+	var err error
+	if m.URI, err = p.field("URI", true); err != nil {
+		return nil, err
+	}
+	if _, err := url.Parse(m.URI); err != nil {
+		return nil, fmt.Errorf("invalid URI: %w", err)
+	}
+
+	if m.Version, err = p.field("Version", true); err != nil {
+		return nil, err
+	}
+	if m.Version != Version {
+		return nil, fmt.Errorf("unsupported version: %s", m.Version)
+	}
+
+	chainID, err := p.field("Chain ID", true)
+	if err != nil {
+		return nil, err
+	}
+	if m.ChainID, err = strconv.ParseInt(chainID, 10, 64); err != nil || m.ChainID <= 0 {
+		return nil, fmt.Errorf("invalid chain ID: %s", chainID)
+	}
+
+	if m.Nonce, err = p.field("Nonce", true); err != nil {
+		return nil, err
+	}
+	if !nonceRe.MatchString(m.Nonce) {
+		return nil, fmt.Errorf("invalid nonce %q: must be at least 8 alphanumeric characters", m.Nonce)
+	}
+
+	issuedAt, err := p.field("Issued At", true)
+	if err != nil {
+		return nil, err
+	}
+	if m.IssuedAt, err = time.Parse(time.RFC3339, issuedAt); err != nil {
+		return nil, fmt.Errorf("invalid Issued At: %w", err)
+	}
+
+	if m.ExpirationTime, err = p.timeField("Expiration Time"); err != nil {
+		return nil, err
+	}
+	if m.NotBefore, err = p.timeField("Not Before"); err != nil {
+		return nil, err
+	}
+	if m.RequestID, err = p.field("Request ID", false); err != nil {
+		return nil, err
+	}
+
+	if p.peek() == "Resources:" {
+		p.next()
+		for p.more() {
+			resource, ok := strings.CutPrefix(p.next(), "- ")
+			if !ok {
+				return nil, fmt.Errorf("invalid resource line %d", p.pos)
+			}
+			m.Resources = append(m.Resources, resource)
+		}
+	}
+
+	if p.more() {
+		return nil, fmt.Errorf("unexpected content on line %d: %q", p.pos+1, p.peek())
+	}
+	return m, nil
+}
+
+// Validate checks the message against what the caller expects and the validity window
+func (m *Message) Validate(want Expectations) error {
+	if want.Domain != "" && !strings.EqualFold(m.Domain, want.Domain) {
+		return fmt.Errorf("domain mismatch: message is for %s, expected %s", m.Domain, want.Domain)
+	}
+	if want.Nonce != "" && m.Nonce != want.Nonce {
+		return fmt.Errorf("nonce mismatch: message has %s, expected %s", m.Nonce, want.Nonce)
+	}
+	if want.ChainID != 0 && m.ChainID != want.ChainID {
+		return fmt.Errorf("chain ID mismatch: message is for chain %d, expected %d", m.ChainID, want.ChainID)
+	}
+
+	now := want.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if m.ExpirationTime != nil && !now.Before(*m.ExpirationTime) {
+		return fmt.Errorf("message expired at %s", m.ExpirationTime.Format(time.RFC3339))
+	}
+	if m.NotBefore != nil && now.Before(*m.NotBefore) {
+		return fmt.Errorf("message is not valid before %s", m.NotBefore.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// parser walks the lines of a message
+type parser struct {
+	lines []string
+	pos   int
+}
+
+func (p *parser) more() bool {
+	return p.pos < len(p.lines)
+}
+
+func (p *parser) peek() string {
+	if !p.more() {
+		return ""
+	}
+	return p.lines[p.pos]
+}
+
+func (p *parser) next() string {
+	line := p.peek()
+	p.pos++
+	return line
+}
+
+// field reads a "Name: value" line; optional fields that are absent return ""
+func (p *parser) field(name string, required bool) (string, error) {
+	value, ok := strings.CutPrefix(p.peek(), name+": ")
+	if !ok {
+		if required {
+			return "", fmt.Errorf("missing %s", name)
+		}
+		return "", nil
+	}
+	p.next()
+	return value, nil
+}
+
+func (p *parser) timeField(name string) (*time.Time, error) {
+	value, err := p.field(name, false)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return &t, nil
+}
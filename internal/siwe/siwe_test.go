@@ -0,0 +1,142 @@
+package siwe
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// The example message from EIP-4361
+const exampleMessage = `example.com wants you to sign in with your Ethereum account:
+0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+
+I accept the ExampleOrg Terms of Service: https://example.com/tos
+
+URI: https://example.com/login
+Version: 1
+Chain ID: 1
+Nonce: 32891756
+Issued At: 2021-09-30T16:25:24Z
+Resources:
+- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/
+- https://example.com/my-web2-claim.json`
+
+func TestParseExample(t *testing.T) {
+	m, err := Parse(exampleMessage)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if m.Scheme != "" || m.Domain != "example.com" {
+		t.Errorf("scheme, domain = %q, %q", m.Scheme, m.Domain)
+	}
+	if m.Address.Hex() != "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2" {
+		t.Errorf("address = %s", m.Address.Hex())
+	}
+	if m.Statement != "I accept the ExampleOrg Terms of Service: https://example.com/tos" {
+		t.Errorf("statement = %q", m.Statement)
+	}
+	if m.URI != "https://example.com/login" || m.Version != "1" || m.ChainID != 1 || m.Nonce != "32891756" {
+		t.Errorf("URI, version, chain ID, nonce = %q, %q, %d, %q", m.URI, m.Version, m.ChainID, m.Nonce)
+	}
+	if want := time.Date(2021, 9, 30, 16, 25, 24, 0, time.UTC); !m.IssuedAt.Equal(want) {
+		t.Errorf("issued at = %s, want %s", m.IssuedAt, want)
+	}
+	if m.ExpirationTime != nil || m.NotBefore != nil || m.RequestID != "" {
+		t.Errorf("unexpected optional fields: %v, %v, %q", m.ExpirationTime, m.NotBefore, m.RequestID)
+	}
+	if len(m.Resources) != 2 || m.Resources[1] != "https://example.com/my-web2-claim.json" {
+		t.Errorf("resources = %q", m.Resources)
+	}
+}
+
+func TestParseOptionalFields(t *testing.T) {
+	text := `https://example.com:3388 wants you to sign in with your Ethereum account:
+0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+
+
+URI: https://example.com/login
+Version: 1
+Chain ID: 137
+Nonce: Xy7rT2kPq9
+Issued At: 2021-09-30T16:25:24Z
+Expiration Time: 2021-10-01T16:25:24Z
+Not Before: 2021-09-30T17:00:00+01:00
+Request ID: request-42`
+
+	m, err := Parse(text)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if m.Scheme != "https" || m.Domain != "example.com:3388" {
+		t.Errorf("scheme, domain = %q, %q", m.Scheme, m.Domain)
+	}
+	if m.Statement != "" {
+		t.Errorf("statement = %q, want none", m.Statement)
+	}
+	if m.ChainID != 137 || m.RequestID != "request-42" || len(m.Resources) != 0 {
+		t.Errorf("chain ID, request ID, resources = %d, %q, %q", m.ChainID, m.RequestID, m.Resources)
+	}
+	if m.ExpirationTime == nil || m.NotBefore == nil {
+		t.Fatal("expiration time and not before not parsed")
+	}
+
+	if err := m.Validate(Expectations{Domain: "EXAMPLE.com:3388", Nonce: "Xy7rT2kPq9", ChainID: 137, Time: *m.NotBefore}); err != nil {
+		t.Errorf("Validate at not before: %v", err)
+	}
+	if err := m.Validate(Expectations{Time: m.NotBefore.Add(-time.Second)}); err == nil {
+		t.Error("Validate before not before succeeded")
+	}
+	if err := m.Validate(Expectations{Time: *m.ExpirationTime}); err == nil {
+		t.Error("Validate at expiration time succeeded")
+	}
+	if err := m.Validate(Expectations{Domain: "evil.com", Time: *m.NotBefore}); err == nil {
+		t.Error("Validate with another domain succeeded")
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	replace := func(old, new string) string {
+		if !strings.Contains(exampleMessage, old) {
+			t.Fatalf("example message has no %q", old)
+		}
+		return strings.Replace(exampleMessage, old, new, 1)
+	}
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", ""},
+		{"missing header", replace("example.com wants you to sign in with your Ethereum account:", "example.com")},
+		{"missing domain", replace("example.com wants", " wants")},
+		{"lowercase address", replace("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2")},
+		{"address without 0x", replace("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", "C02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")},
+		{"no empty line after address", replace("Cc2\n\n", "Cc2\n")},
+		{"two-line statement", replace("Terms of Service: https://example.com/tos", "Terms of Service:\nhttps://example.com/tos")},
+		{"missing URI", replace("URI: https://example.com/login\n", "")},
+		{"wrong version", replace("Version: 1", "Version: 2")},
+		{"zero chain ID", replace("Chain ID: 1", "Chain ID: 0")},
+		{"hex chain ID", replace("Chain ID: 1", "Chain ID: 0x1")},
+		{"short nonce", replace("Nonce: 32891756", "Nonce: 1234567")},
+		{"nonce with symbols", replace("Nonce: 32891756", "Nonce: 32891756!")},
+		{"bad issued at", replace("2021-09-30T16:25:24Z", "2021-09-30 16:25:24")},
+		{"bad expiration time", replace("Resources:", "Expiration Time: tomorrow\nResources:")},
+		{"fields out of order", replace("Version: 1\nChain ID: 1", "Chain ID: 1\nVersion: 1")},
+		{"bad resource line", replace("- https://example.com/my-web2-claim.json", "https://example.com/my-web2-claim.json")},
+		{"trailing content", exampleMessage + "\nextra"},
+		{"carriage return", strings.ReplaceAll(exampleMessage, "\n", "\r\n")},
+		{"escape sequence in statement", replace("I accept", "\x1b[2K\rI accept")},
+		{"escape sequence in URI", replace("https://example.com/login", "https://example.com/login\x1b[1A")},
+		{"bidi override in resource", replace("my-web2-claim", "my-\u202eweb2-claim")},
+		{"tab in request ID", replace("Resources:", "Request ID: a\tb\nResources:")},
+		{"invalid UTF-8", replace("ExampleOrg", "Example\xffOrg")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if m, err := Parse(tt.text); err == nil {
+				t.Fatalf("Parse succeeded: %+v", m)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// EtherDecimals is the number of decimals of native EVM currencies (wei per ether = 10^18)
+const EtherDecimals = 18
+
+// GweiDecimals is the number of decimals of gwei, the unit gas prices are quoted in
+const GweiDecimals = 9
+
+// ParseUnits parses a decimal string such as "0.1" into base units with the given number
+// of decimals. The conversion is exact; amounts with more fractional digits than decimals
+// are rejected instead of rounded, as are amounts above the uint256 maximum.
+func ParseUnits(amount string, decimals int) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+	if amount == "" {
+		return nil, errors.New("empty amount")
+	}
+	if decimals < 0 {
+		return nil, fmt.Errorf("invalid decimals: %d", decimals)
+	}
+
+	whole, frac, _ := strings.Cut(amount, ".")
+	if whole == "" && frac == "" {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > decimals {
+		return nil, fmt.Errorf("amount %s has more than %d decimal places", amount, decimals)
+	}
+
+	digits := whole + frac + strings.Repeat("0", decimals-len(frac))
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+	// Amounts are encoded as uint256 in transactions and calldata
+	if value.BitLen() > 256 {
+		return nil, fmt.Errorf("amount %s is too large", amount)
+	}
+	return value, nil
+}
+
+// FormatUnits formats base units as a decimal string without rounding, trimming
+// trailing zeros of the fractional part
+func FormatUnits(value *big.Int, decimals int) string {
+	if decimals <= 0 {
+		return value.String()
+	}
+
+	sign := ""
+	abs := new(big.Int).Set(value)
+	if abs.Sign() < 0 {
+		sign = "-"
+		abs.Neg(abs)
+	}
+
+	digits := abs.String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	whole := digits[:len(digits)-decimals]
+	frac := strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
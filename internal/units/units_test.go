@@ -0,0 +1,93 @@
+package units
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParseUnits(t *testing.T) {
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	tests := []struct {
+		name     string
+		amount   string
+		decimals int
+		want     string // base units; empty when an error is expected
+	}{
+		{"whole", "1", 18, "1000000000000000000"},
+		{"fraction", "0.1", 18, "100000000000000000"},
+		{"leading dot", ".5", 6, "500000"},
+		{"trailing dot", "5.", 6, "5000000"},
+		{"trailing zeros", "1.500000000", 6, "1500000"},
+		{"trailing zeros beyond decimals", "2.000", 0, "2"},
+		{"smallest unit", "0.000001", 6, "1"},
+		{"zero decimals", "42", 0, "42"},
+		{"surrounding space", " 3 ", 0, "3"},
+		{"max uint256", maxUint256.String(), 0, maxUint256.String()},
+		{"too many decimals", "0.0000001", 6, ""},
+		{"fraction with zero decimals", "1.5", 0, ""},
+		{"overflow", new(big.Int).Add(maxUint256, big.NewInt(1)).String(), 0, ""},
+		{"overflow after scaling", "1" + strings.Repeat("0", 60), 18, ""},
+		{"empty", "", 18, ""},
+		{"only dot", ".", 18, ""},
+		{"negative", "-1", 18, ""},
+		{"exponent", "1e18", 0, ""},
+		{"two dots", "1.2.3", 18, ""},
+		{"negative decimals", "1", -1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUnits(tt.amount, tt.decimals)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("ParseUnits(%q, %d) = %s, want error", tt.amount, tt.decimals, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseUnits(%q, %d) failed: %v", tt.amount, tt.decimals, err)
+			}
+			if got.String() != tt.want {
+				t.Fatalf("ParseUnits(%q, %d) = %s, want %s", tt.amount, tt.decimals, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		value    string
+		decimals int
+		want     string
+	}{
+		{"1000000000000000000", 18, "1"},
+		{"100000000000000000", 18, "0.1"},
+		{"1", 18, "0.000000000000000001"},
+		{"1500000", 6, "1.5"},
+		{"0", 6, "0"},
+		{"42", 0, "42"},
+		{"-1500000", 6, "-1.5"},
+		{"123456789", 9, "0.123456789"},
+	}
+
+	for _, tt := range tests {
+		value, _ := new(big.Int).SetString(tt.value, 10)
+		if got := FormatUnits(value, tt.decimals); got != tt.want {
+			t.Errorf("FormatUnits(%s, %d) = %s, want %s", tt.value, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, amount := range []string{"0", "1", "0.5", "123.456", "0.000000000000000001"} {
+		value, err := ParseUnits(amount, EtherDecimals)
+		if err != nil {
+			t.Fatalf("ParseUnits(%q) failed: %v", amount, err)
+		}
+		if got := FormatUnits(value, EtherDecimals); got != amount {
+			t.Errorf("FormatUnits(ParseUnits(%q)) = %s", amount, got)
+		}
+	}
+}
@@ -1,70 +1,109 @@
 package wallet
 
 import (
-	"errors"
+	"crypto/ecdsa"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/tyler-smith/go-bip32"
-	"github.com/tyler-smith/go-bip39"
 )
 
-// GenerateMnemonic generates a new 12-word BIP39 mnemonic
-func GenerateMnemonic() (string, error) {
-	entropy, err := bip39.NewEntropy(128)
-	if err != nil {
-		return "", err
-	}
-	return bip39.NewMnemonic(entropy)
+// HDWallet is the BIP32 master key of a mnemonic, used to derive accounts
+type HDWallet struct {
+	master *bip32.Key
+	// ID is the BIP32 fingerprint of the master key, used to tell seeds apart
+	ID string
+	// Language is the wordlist the mnemonic was written in
+	Language string
 }
 
-// ImportMnemonic derives a private key from a mnemonic and imports it into the keystore
-// Uses BIP44 path: m/44'/60'/0'/0/0 (Standard Ethereum path for first account)
-func (s *Service) ImportMnemonic(mnemonic, password string) (accounts.Account, error) {
-	if !bip39.IsMnemonicValid(mnemonic) {
-		return accounts.Account{}, errors.New("invalid mnemonic")
+// NewHDWallet validates a mnemonic and derives its master key
+// passphrase is the optional BIP39 passphrase ("25th word"); each passphrase yields a different seed
+func NewHDWallet(mnemonic, passphrase string) (*HDWallet, error) {
+	language, err := DetectLanguage(mnemonic)
+	if err != nil {
+		return nil, err
 	}
 
-	seed := bip39.NewSeed(mnemonic, "")
+	seed := mnemonicSeed(mnemonic, passphrase)
 	masterKey, err := bip32.NewMasterKey(seed)
 	if err != nil {
-		return accounts.Account{}, err
+		return nil, err
 	}
 
-	// m/44'/60'/0'/0/0
-	// 44'
-	purpose, err := masterKey.NewChildKey(bip32.FirstHardenedChild + 44)
+	// Any direct child carries the parent's fingerprint
+	child, err := masterKey.NewChildKey(bip32.FirstHardenedChild + 44)
 	if err != nil {
-		return accounts.Account{}, err
+		return nil, err
 	}
-	// 60' (ETH)
-	coinType, err := purpose.NewChildKey(bip32.FirstHardenedChild + 60)
-	if err != nil {
-		return accounts.Account{}, err
+
+	return &HDWallet{
+		master:   masterKey,
+		ID:       hex.EncodeToString(child.FingerPrint),
+		Language: language,
+	}, nil
+}
+
+// DeriveKey derives the private key at the given BIP32 path
+func (w *HDWallet) DeriveKey(path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	key := w.master
+	for _, component := range path {
+		child, err := key.NewChildKey(component)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive %s: %w", path, err)
+		}
+		key = child
 	}
-	// 0' (Account)
-	accountKey, err := coinType.NewChildKey(bip32.FirstHardenedChild + 0)
+	return crypto.ToECDSA(key.Key)
+}
+
+// Address returns the address at the given path without importing it
+func (w *HDWallet) Address(path accounts.DerivationPath) (common.Address, error) {
+	privateKey, err := w.DeriveKey(path)
 	if err != nil {
-		return accounts.Account{}, err
+		return common.Address{}, err
 	}
-	// 0 (Change)
-	change, err := accountKey.NewChildKey(0)
+	return crypto.PubkeyToAddress(privateKey.PublicKey), nil
+}
+
+// ImportHDAccount derives the account at the given index of pathSpec and imports it
+// into the keystore, recording which seed and path it came from
+func (s *Service) ImportHDAccount(w *HDWallet, pathSpec string, index uint32, password string) (accounts.Account, error) {
+	path, err := ResolvePath(pathSpec, index)
 	if err != nil {
 		return accounts.Account{}, err
 	}
-	// 0 (Index)
-	addressKey, err := change.NewChildKey(0)
+
+	privateKey, err := w.DeriveKey(path)
 	if err != nil {
 		return accounts.Account{}, err
 	}
 
-	privateKey, err := crypto.ToECDSA(addressKey.Key)
+	acc, err := s.ks.ImportECDSA(privateKey, password)
 	if err != nil {
 		return accounts.Account{}, err
 	}
 
-	return s.ks.ImportECDSA(privateKey, password)
+	return acc, s.recordMeta(acc, AccountMeta{Source: SourceMnemonic, Seed: w.ID, Index: index, Path: path.String()})
+}
+
+// NextIndex returns the first index after the highest one already derived from the seed
+// along pathSpec
+func (s *Service) NextIndex(w *HDWallet, pathSpec string) uint32 {
+	var next uint32
+	for _, acc := range s.ks.Accounts() {
+		meta := s.meta.get(acc.Address)
+		if meta.Seed != w.ID || meta.Index < next {
+			continue
+		}
+		if path, err := ResolvePath(pathSpec, meta.Index); err == nil && path.String() == meta.Path {
+			next = meta.Index + 1
+		}
+	}
+	return next
 }
 
 // ImportPrivateKey imports a raw private key hex string
@@ -73,5 +112,10 @@ func (s *Service) ImportPrivateKey(privateKeyHex, password string) (accounts.Acc
 	if err != nil {
 		return accounts.Account{}, fmt.Errorf("invalid private key: %w", err)
 	}
-	return s.ks.ImportECDSA(privateKey, password)
+
+	acc, err := s.ks.ImportECDSA(privateKey, password)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	return acc, s.recordMeta(acc, AccountMeta{Source: SourcePrivateKey})
 }
@@ -1,19 +1,27 @@
 package wallet
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
 type Service struct {
-	ks *keystore.KeyStore
+	ks   *keystore.KeyStore
+	meta *metadataStore
+	dir  string
 }
 
 func NewService() (*Service, error) {
@@ -22,7 +30,8 @@ func NewService() (*Service, error) {
 		return nil, err
 	}
 
-	keystorePath := filepath.Join(home, ".tokit", "keystore")
+	dir := filepath.Join(home, ".tokit")
+	keystorePath := filepath.Join(dir, "keystore")
 	if err := os.MkdirAll(keystorePath, 0700); err != nil {
 		return nil, err
 	}
@@ -30,21 +39,147 @@ func NewService() (*Service, error) {
 	// Use StandardScryptN and StandardScryptP for better security
 	ks := keystore.NewKeyStore(keystorePath, keystore.StandardScryptN, keystore.StandardScryptP)
 
-	return &Service{ks: ks}, nil
+	meta, err := loadMetadata(filepath.Join(dir, "accounts.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account metadata: %w", err)
+	}
+
+	return &Service{ks: ks, meta: meta, dir: dir}, nil
 }
 
 func (s *Service) CreateAccount(password string) (accounts.Account, error) {
-	return s.ks.NewAccount(password)
+	acc, err := s.ks.NewAccount(password)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	return acc, s.recordMeta(acc, AccountMeta{Source: SourceRandom})
 }
 
 func (s *Service) ImportAccount(keyJSON []byte, password, newPassword string) (accounts.Account, error) {
-	return s.ks.Import(keyJSON, password, newPassword)
+	acc, err := s.ks.Import(keyJSON, password, newPassword)
+	if err != nil {
+		// acc still carries the address when the key is already in the keystore
+		return acc, err
+	}
+	return acc, s.recordMeta(acc, AccountMeta{Source: SourceKeystore})
+}
+
+// ExportKeystore returns the account's key as V3 keystore JSON encrypted with the same password
+func (s *Service) ExportKeystore(account accounts.Account, password string) ([]byte, error) {
+	return s.ks.Export(account, password, password)
+}
+
+// ExportPrivateKey decrypts the account's key file and returns the raw private key as hex
+func (s *Service) ExportPrivateKey(account accounts.Account, password string) (string, error) {
+	keyJSON, err := os.ReadFile(account.URL.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key file: %w", err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(crypto.FromECDSA(key.PrivateKey)), nil
+}
+
+// UpdatePassword re-encrypts the account's key under a new password
+func (s *Service) UpdatePassword(account accounts.Account, password, newPassword string) error {
+	return s.ks.Update(account, password, newPassword)
+}
+
+// DeleteAccount removes the account from the keystore after copying its key file to
+// ~/.tokit/backup, and returns the path of the backup
+func (s *Service) DeleteAccount(account accounts.Account, password string) (string, error) {
+	keyJSON, err := os.ReadFile(account.URL.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	backupDir := filepath.Join(s.dir, "backup")
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return "", err
+	}
+	backupPath := filepath.Join(backupDir, filepath.Base(account.URL.Path))
+	if err := os.WriteFile(backupPath, keyJSON, 0600); err != nil {
+		return "", fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	if err := s.ks.Delete(account, password); err != nil {
+		os.Remove(backupPath)
+		return "", err
+	}
+
+	if err := s.meta.delete(account.Address); err != nil {
+		return backupPath, fmt.Errorf("account removed but failed to update metadata: %w", err)
+	}
+	return backupPath, nil
+}
+
+// IsKeyJSON reports whether data is a V3 (Web3 Secret Storage) keystore file
+func IsKeyJSON(data []byte) bool {
+	var key struct {
+		Version int             `json:"version"`
+		Crypto  json.RawMessage `json:"crypto"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return false
+	}
+	return key.Version == 3 && len(key.Crypto) > 0
+}
+
+func (s *Service) recordMeta(acc accounts.Account, meta AccountMeta) error {
+	if err := s.meta.set(acc.Address, meta); err != nil {
+		return fmt.Errorf("account imported but failed to save metadata: %w", err)
+	}
+	return nil
 }
 
 func (s *Service) ListAccounts() []accounts.Account {
 	return s.ks.Accounts()
 }
 
+// AccountMeta returns the locally stored metadata for an account
+func (s *Service) AccountMeta(addr common.Address) AccountMeta {
+	return s.meta.get(addr)
+}
+
+// SetLabel assigns a unique label to an account; an empty label removes it
+func (s *Service) SetLabel(addr common.Address, label string) error {
+	label = strings.TrimSpace(label)
+	if label != "" {
+		if common.IsHexAddress(label) {
+			return fmt.Errorf("label must not look like an address: %s", label)
+		}
+		if _, err := strconv.Atoi(label); err == nil {
+			return fmt.Errorf("label must not be a number: %s", label)
+		}
+		if other, ok := s.meta.findLabel(label); ok && other != addr {
+			return fmt.Errorf("label %q is already used by %s", label, other.Hex())
+		}
+	}
+
+	meta := s.meta.get(addr)
+	meta.Label = label
+	return s.meta.set(addr, meta)
+}
+
+// SetTags replaces the tag list of an account
+func (s *Service) SetTags(addr common.Address, tags []string) error {
+	meta := s.meta.get(addr)
+	meta.Tags = nil
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			meta.Tags = append(meta.Tags, tag)
+		}
+	}
+	return s.meta.set(addr, meta)
+}
+
+// HasAccount reports whether the address is present in the keystore
+func (s *Service) HasAccount(addr common.Address) bool {
+	return s.ks.HasAddress(addr)
+}
+
 func (s *Service) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int, password string) (*types.Transaction, error) {
 	if err := s.ks.Unlock(account, password); err != nil {
 		return nil, fmt.Errorf("failed to unlock account: %w", err)
@@ -56,16 +191,55 @@ func (s *Service) SignTx(account accounts.Account, tx *types.Transaction, chainI
 	return s.ks.SignTxWithPassphrase(account, password, tx, chainID)
 }
 
-func (s *Service) GetAccount(addressHex string) (accounts.Account, error) {
-	if !common.IsHexAddress(addressHex) {
-		return accounts.Account{}, fmt.Errorf("invalid address: %s", addressHex)
+// SignMessage signs msg as an EIP-191 personal message (personal_sign) and returns the
+// 65-byte signature with v as 27 or 28
+func (s *Service) SignMessage(account accounts.Account, msg []byte, password string) ([]byte, error) {
+	sig, err := s.ks.SignHashWithPassphrase(account, password, accounts.TextHash(msg))
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// SignTypedData signs EIP-712 typed data (eth_signTypedData_v4) and returns the 65-byte
+// signature with v as 27 or 28
+func (s *Service) SignTypedData(account accounts.Account, typedData apitypes.TypedData, password string) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid typed data: %w", err)
+	}
+	sig, err := s.ks.SignHashWithPassphrase(account, password, hash)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// GetAccount resolves an account by address, label, or its index in ListAccounts
+func (s *Service) GetAccount(ref string) (accounts.Account, error) {
+	if addr, ok := s.meta.findLabel(ref); ok {
+		ref = addr.Hex()
 	}
-	addr := common.HexToAddress(addressHex)
 
-	for _, acc := range s.ks.Accounts() {
-		if acc.Address == addr {
-			return acc, nil
+	if common.IsHexAddress(ref) {
+		addr := common.HexToAddress(ref)
+		for _, acc := range s.ks.Accounts() {
+			if acc.Address == addr {
+				return acc, nil
+			}
 		}
+		return accounts.Account{}, fmt.Errorf("account not found: %s", ref)
 	}
-	return accounts.Account{}, fmt.Errorf("account not found: %s", addressHex)
+
+	if index, err := strconv.Atoi(ref); err == nil {
+		accs := s.ks.Accounts()
+		if index < 0 || index >= len(accs) {
+			return accounts.Account{}, fmt.Errorf("account index %d out of range (have %d accounts)", index, len(accs))
+		}
+		return accs[index], nil
+	}
+
+	return accounts.Account{}, fmt.Errorf("unknown account: %s (expected an address, label or list index)", ref)
 }
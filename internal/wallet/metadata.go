@@ -0,0 +1,90 @@
+package wallet
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Account creation sources recorded in AccountMeta
+const (
+	SourceRandom     = "random"
+	SourceMnemonic   = "mnemonic"
+	SourcePrivateKey = "private-key"
+	SourceKeystore   = "keystore"
+)
+
+// AccountMeta holds the locally tracked details of a keystore account
+type AccountMeta struct {
+	Label  string   `json:"label,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Source string   `json:"source,omitempty"`
+	// Seed is the fingerprint of the mnemonic the account was derived from,
+	// empty for accounts that were not derived from a seed
+	Seed  string `json:"seed,omitempty"`
+	Index uint32 `json:"index,omitempty"`
+	Path  string `json:"path,omitempty"`
+}
+
+// metadataStore persists AccountMeta entries as a JSON file next to the keystore
+type metadataStore struct {
+	path     string
+	Accounts map[common.Address]AccountMeta `json:"accounts"`
+}
+
+func loadMetadata(path string) (*metadataStore, error) {
+	store := &metadataStore{
+		path:     path,
+		Accounts: make(map[common.Address]AccountMeta),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, err
+	}
+	if store.Accounts == nil {
+		store.Accounts = make(map[common.Address]AccountMeta)
+	}
+	return store, nil
+}
+
+func (m *metadataStore) get(addr common.Address) AccountMeta {
+	return m.Accounts[addr]
+}
+
+// findLabel returns the address carrying the label, compared case-insensitively
+func (m *metadataStore) findLabel(label string) (common.Address, bool) {
+	for addr, meta := range m.Accounts {
+		if meta.Label != "" && strings.EqualFold(meta.Label, label) {
+			return addr, true
+		}
+	}
+	return common.Address{}, false
+}
+
+func (m *metadataStore) set(addr common.Address, meta AccountMeta) error {
+	m.Accounts[addr] = meta
+	return m.save()
+}
+
+func (m *metadataStore) delete(addr common.Address) error {
+	delete(m.Accounts, addr)
+	return m.save()
+}
+
+func (m *metadataStore) save() error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.path, data, 0600)
+}
@@ -0,0 +1,113 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultLanguage is the wordlist used when none is given
+const DefaultLanguage = "english"
+
+// languages lists the supported BIP39 wordlists in the order they are tried when detecting
+var languages = []struct {
+	name  string
+	words []string
+}{
+	{"english", wordlists.English},
+	{"japanese", wordlists.Japanese},
+	{"spanish", wordlists.Spanish},
+	{"chinese-simplified", wordlists.ChineseSimplified},
+	{"chinese-traditional", wordlists.ChineseTraditional},
+	{"french", wordlists.French},
+	{"italian", wordlists.Italian},
+	{"korean", wordlists.Korean},
+	{"czech", wordlists.Czech},
+}
+
+// Languages returns the names of the supported wordlists
+func Languages() []string {
+	names := make([]string, len(languages))
+	for i, l := range languages {
+		names[i] = l.name
+	}
+	return names
+}
+
+// GenerateMnemonic generates a new BIP39 mnemonic of 12, 15, 18, 21 or 24 words
+func GenerateMnemonic(words int, language string) (string, error) {
+	if words%3 != 0 || words < 12 || words > 24 {
+		return "", fmt.Errorf("invalid word count %d: must be 12, 15, 18, 21 or 24", words)
+	}
+
+	list, err := wordList(language)
+	if err != nil {
+		return "", err
+	}
+
+	// Every 3 words encode 32 bits of entropy plus a 1-bit checksum
+	entropy, err := bip39.NewEntropy(words / 3 * 32)
+	if err != nil {
+		return "", err
+	}
+
+	var mnemonic string
+	withWordList(list, func() {
+		mnemonic, err = bip39.NewMnemonic(entropy)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// BIP39 specifies the ideographic space as separator for Japanese
+	if strings.EqualFold(language, "japanese") {
+		mnemonic = strings.ReplaceAll(mnemonic, " ", "　")
+	}
+	return mnemonic, nil
+}
+
+// DetectLanguage returns the name of the wordlist the mnemonic is valid in
+func DetectLanguage(mnemonic string) (string, error) {
+	mnemonic = normalizeMnemonic(mnemonic)
+	for _, l := range languages {
+		var valid bool
+		withWordList(l.words, func() {
+			valid = bip39.IsMnemonicValid(mnemonic)
+		})
+		if valid {
+			return l.name, nil
+		}
+	}
+	return "", errors.New("invalid mnemonic")
+}
+
+// mnemonicSeed derives the BIP39 seed, applying the NFKD normalization the spec requires
+func mnemonicSeed(mnemonic, passphrase string) []byte {
+	return bip39.NewSeed(normalizeMnemonic(mnemonic), norm.NFKD.String(passphrase))
+}
+
+// normalizeMnemonic decomposes characters the way the wordlists store them (NFKD) and
+// collapses any whitespace, including ideographic spaces, into single spaces
+func normalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(norm.NFKD.String(mnemonic)), " ")
+}
+
+func wordList(language string) ([]string, error) {
+	for _, l := range languages {
+		if strings.EqualFold(l.name, language) {
+			return l.words, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported mnemonic language: %s", language)
+}
+
+// withWordList runs fn with the package-wide go-bip39 wordlist switched to list
+func withWordList(list []string, fn func()) {
+	defer bip39.SetWordList(wordlists.English)
+	bip39.SetWordList(list)
+	fn()
+}
@@ -0,0 +1,56 @@
+package wallet
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+)
+
+// DefaultPathPreset is used when no derivation path is given
+const DefaultPathPreset = "bip44"
+
+// PathPresets maps preset names to derivation path templates, where N is the account index
+var PathPresets = map[string]string{
+	"bip44":       "m/44'/60'/0'/0/N", // MetaMask, Trezor, most software wallets
+	"ledger-live": "m/44'/60'/N'/0/0", // Ledger Live
+	"legacy":      "m/44'/60'/0'/N",   // MyEtherWallet, old Ledger Chrome app
+}
+
+// PresetNames returns the sorted preset names, for help text
+func PresetNames() []string {
+	names := make([]string, 0, len(PathPresets))
+	for name := range PathPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PathTemplate expands a preset name into its template; anything else is returned as is
+func PathTemplate(spec string) string {
+	if template, ok := PathPresets[strings.ToLower(spec)]; ok {
+		return template
+	}
+	return spec
+}
+
+// IsIndexedPath reports whether the preset or template contains an account index placeholder
+func IsIndexedPath(spec string) bool {
+	return strings.Contains(PathTemplate(spec), "N")
+}
+
+// ResolvePath turns a preset name, a template containing N, or a literal BIP32 path
+// into a derivation path for the given account index
+func ResolvePath(spec string, index uint32) (accounts.DerivationPath, error) {
+	template := PathTemplate(spec)
+	path := strings.ReplaceAll(template, "N", strconv.FormatUint(uint64(index), 10))
+
+	parsed, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid derivation path %q: %w", spec, err)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,261 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	seedVersion = 1
+	seedKeyLen  = 32
+	seedScryptR = 8
+)
+
+// ErrSeedNotFound is returned when no seed with the given fingerprint is stored
+var ErrSeedNotFound = errors.New("seed not found")
+
+// SeedInfo describes a stored seed without decrypting it
+type SeedInfo struct {
+	ID       string
+	Language string
+	Path     string
+}
+
+type seedFile struct {
+	Version  int        `json:"version"`
+	ID       string     `json:"id"`
+	Language string     `json:"language"`
+	Crypto   seedCrypto `json:"crypto"`
+}
+
+type seedCrypto struct {
+	KDF        string          `json:"kdf"`
+	KDFParams  seedScryptParam `json:"kdfparams"`
+	Cipher     string          `json:"cipher"`
+	Nonce      string          `json:"nonce"`
+	CipherText string          `json:"ciphertext"`
+}
+
+type seedScryptParam struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// seedSecret is the plaintext sealed inside a seed file
+type seedSecret struct {
+	Mnemonic   string `json:"mnemonic"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+func (s *Service) seedDir() string {
+	return filepath.Join(s.dir, "seeds")
+}
+
+// seedPath returns the file of a seed; the id must be a 4-byte hex fingerprint so it can't
+// point outside the seed directory
+func (s *Service) seedPath(id string) (string, error) {
+	id = strings.ToLower(id)
+	if raw, err := hex.DecodeString(id); err != nil || len(raw) != 4 {
+		return "", fmt.Errorf("invalid seed fingerprint %q (expected 8 hex characters)", id)
+	}
+	return filepath.Join(s.seedDir(), id+".json"), nil
+}
+
+// SaveSeed encrypts the mnemonic and BIP39 passphrase with scrypt and AES-256-GCM and stores
+// them under ~/.tokit/seeds, named by seed fingerprint. It returns false if the seed was
+// already stored, in which case the existing file is left untouched.
+func (s *Service) SaveSeed(w *HDWallet, mnemonic, passphrase, password string) (bool, error) {
+	path, err := s.seedPath(w.ID)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+
+	plaintext, err := json.Marshal(seedSecret{Mnemonic: normalizeMnemonic(mnemonic), Passphrase: passphrase})
+	if err != nil {
+		return false, err
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return false, err
+	}
+	params := seedScryptParam{
+		N:     keystore.StandardScryptN,
+		R:     seedScryptR,
+		P:     keystore.StandardScryptP,
+		DKLen: seedKeyLen,
+		Salt:  hex.EncodeToString(salt),
+	}
+
+	gcm, err := seedCipher(password, params)
+	if err != nil {
+		return false, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return false, err
+	}
+
+	// The fingerprint is bound as additional data so files can't be swapped around
+	ciphertext := gcm.Seal(nil, nonce, plaintext, []byte(w.ID))
+
+	data, err := json.MarshalIndent(seedFile{
+		Version:  seedVersion,
+		ID:       w.ID,
+		Language: w.Language,
+		Crypto: seedCrypto{
+			KDF:        "scrypt",
+			KDFParams:  params,
+			Cipher:     "aes-256-gcm",
+			Nonce:      hex.EncodeToString(nonce),
+			CipherText: hex.EncodeToString(ciphertext),
+		},
+	}, "", "  ")
+	if err != nil {
+		return false, err
+	}
+
+	if err := os.MkdirAll(s.seedDir(), 0700); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListSeeds returns the seeds stored in the vault
+func (s *Service) ListSeeds() ([]SeedInfo, error) {
+	entries, err := os.ReadDir(s.seedDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var seeds []SeedInfo
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		file, err := readSeedFile(filepath.Join(s.seedDir(), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		seeds = append(seeds, SeedInfo{ID: file.ID, Language: file.Language, Path: filepath.Join(s.seedDir(), entry.Name())})
+	}
+	sort.Slice(seeds, func(i, j int) bool { return seeds[i].ID < seeds[j].ID })
+	return seeds, nil
+}
+
+// ExportSeed decrypts a stored seed and returns its mnemonic and BIP39 passphrase
+func (s *Service) ExportSeed(id, password string) (string, string, error) {
+	path, err := s.seedPath(id)
+	if err != nil {
+		return "", "", err
+	}
+	file, err := readSeedFile(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	gcm, err := seedCipher(password, file.Crypto.KDFParams)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := hex.DecodeString(file.Crypto.Nonce)
+	if err != nil {
+		return "", "", fmt.Errorf("corrupt seed file: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(file.Crypto.CipherText)
+	if err != nil {
+		return "", "", fmt.Errorf("corrupt seed file: %w", err)
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(file.ID))
+	if err != nil {
+		return "", "", errors.New("could not decrypt seed with given password")
+	}
+
+	var secret seedSecret
+	if err := json.Unmarshal(plaintext, &secret); err != nil {
+		return "", "", fmt.Errorf("corrupt seed file: %w", err)
+	}
+	return secret.Mnemonic, secret.Passphrase, nil
+}
+
+// OpenSeed decrypts a stored seed and returns its HD wallet
+func (s *Service) OpenSeed(id, password string) (*HDWallet, error) {
+	mnemonic, passphrase, err := s.ExportSeed(id, password)
+	if err != nil {
+		return nil, err
+	}
+	return NewHDWallet(mnemonic, passphrase)
+}
+
+// DeleteSeed removes a stored seed after checking the password
+func (s *Service) DeleteSeed(id, password string) error {
+	path, err := s.seedPath(id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := s.ExportSeed(id, password); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func readSeedFile(path string) (*seedFile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrSeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file seedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid seed file %s: %w", path, err)
+	}
+	if file.Version != seedVersion {
+		return nil, fmt.Errorf("unsupported seed file version %d", file.Version)
+	}
+	return &file, nil
+}
+
+// seedCipher derives the AES-256-GCM cipher for a seed file from the password
+func seedCipher(password string, params seedScryptParam) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt seed file: %w", err)
+	}
+	key, err := scrypt.Key([]byte(password), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,47 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// RecoverMessage returns the address that produced an EIP-191 personal signature over msg
+func RecoverMessage(msg, sig []byte) (common.Address, error) {
+	return recoverHash(accounts.TextHash(msg), sig)
+}
+
+// RecoverTypedData returns the address that produced an EIP-712 signature over typedData
+func RecoverTypedData(typedData apitypes.TypedData, sig []byte) (common.Address, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid typed data: %w", err)
+	}
+	return recoverHash(hash, sig)
+}
+
+// recoverHash recovers the signer of a 65-byte [R || S || V] signature, accepting V as
+// 0/1 or 27/28
+func recoverHash(hash, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature must be %d bytes, got %d", crypto.SignatureLength, len(sig))
+	}
+
+	sig = append([]byte(nil), sig...)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	if sig[crypto.RecoveryIDOffset] > 1 {
+		return common.Address{}, errors.New("invalid signature recovery id")
+	}
+
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}